@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/config"
+	"envpick/internal/text"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   text.Text.Commands.Cache.Use,
+	Short: text.Text.Commands.Cache.Short,
+	Long:  text.Text.Commands.Cache.Long,
+}
+
+// cacheClearCmd represents the cache clear subcommand
+var cacheClearCmd = &cobra.Command{
+	Use:   text.Text.Commands.CacheClear.Use,
+	Short: text.Text.Commands.CacheClear.Short,
+	Long:  text.Text.Commands.CacheClear.Long,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		resolver, err := config.NewResolver()
+		if err != nil {
+			return err
+		}
+
+		if err := resolver.Cache.Clear(); err != nil {
+			return err
+		}
+
+		fmt.Print(text.Text.Messages.CacheCleared)
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/config"
+	"envpick/internal/core"
+	"envpick/internal/text"
+	"envpick/internal/tomledit"
+)
+
+var (
+	capturePrefixFlag []string
+	captureKeysFlag   []string
+	captureYesFlag    bool
+)
+
+var captureCmd = &cobra.Command{
+	Use:   text.Text.Commands.Capture.Use,
+	Short: text.Text.Commands.Capture.Short,
+	Long:  text.Text.Commands.Capture.Long,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(capturePrefixFlag) == 0 && len(captureKeysFlag) == 0 {
+			return errors.New(text.Text.Errors.CaptureNoFilter)
+		}
+
+		// A missing config file simply means this is the first configuration
+		cfg, err := config.LoadConfigOrEmpty()
+		if err != nil {
+			return err
+		}
+
+		target := config.BuildConfigName(namespaceFlag, args[0])
+		if err := checkNewName(cfg, target); err != nil {
+			return err
+		}
+
+		vars, missing, err := core.CaptureEnv(core.ParseEnviron(os.Environ()), capturePrefixFlag, captureKeysFlag)
+		if err != nil {
+			return err
+		}
+		for _, k := range missing {
+			fmt.Fprintf(os.Stderr, text.Text.Messages.CaptureMissingKey, k)
+		}
+		if len(vars) == 0 {
+			return errors.New(text.Text.Errors.CaptureNothing)
+		}
+
+		fingerprintKey, err := config.LoadOrCreateFingerprintKey()
+		if err != nil {
+			return err
+		}
+		keys := sortedKeys(vars)
+		fmt.Printf(text.Text.Messages.CapturePreview, len(keys), target)
+		for _, k := range keys {
+			value := vars[k]
+			if config.IsSecretKeyName(k) {
+				value = maskValue(fingerprintKey, value)
+			}
+			fmt.Printf(text.Text.Formats.CaptureRow, k, value)
+		}
+
+		if !captureYesFlag {
+			ok, err := confirm(fmt.Sprintf(text.Text.Prompts.ConfirmWriteProfile, target))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errors.New(text.Text.Errors.Aborted)
+			}
+		}
+
+		values := make([]tomledit.KeyValue, 0, len(keys))
+		for _, k := range keys {
+			values = append(values, tomledit.KeyValue{Key: k, Value: vars[k]})
+		}
+
+		err = config.UpdateConfigFile(func(doc *tomledit.Document) error {
+			doc.AppendTable(target, values)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf(text.Text.Messages.WroteProfile, target)
+		return nil
+	},
+}
+
+func init() {
+	captureCmd.Flags().StringArrayVar(&capturePrefixFlag, "prefix", nil, text.Text.Commands.Flags.CapturePrefix)
+	captureCmd.Flags().StringSliceVar(&captureKeysFlag, "keys", nil, text.Text.Commands.Flags.CaptureKeys)
+	captureCmd.Flags().BoolVarP(&captureYesFlag, "yes", "y", false, text.Text.Commands.Flags.Yes)
+}
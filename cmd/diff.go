@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/config"
+	"envpick/internal/core"
+	"envpick/internal/text"
+)
+
+var diffFormatFlag string
+
+var diffCmd = &cobra.Command{
+	Use:   text.Text.Commands.Diff.Use,
+	Short: text.Text.Commands.Diff.Short,
+	Long:  text.Text.Commands.Diff.Long,
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngineWithNamespace(namespaceFlag)
+		if err != nil {
+			return err
+		}
+		cfg := engine.GetConfig()
+
+		// Names are full names (db.prod) unless -n is given
+		names := make([]string, len(args))
+		for i, arg := range args {
+			names[i] = config.BuildConfigName(namespaceFlag, arg)
+			if _, ok := cfg.Configs[names[i]]; !ok {
+				return fmt.Errorf(text.Text.Errors.ConfigNotFound, arg)
+			}
+		}
+
+		// With a single name, compare the current configuration of its namespace
+		if len(names) == 1 {
+			ns, _ := config.ParseConfigName(names[0])
+			current, err := core.NewEngineWithNamespace(ns)
+			if err != nil {
+				return err
+			}
+			if current.GetCurrentConfigFull() == "" {
+				return errors.New(text.Text.Errors.NoCurrentConfig)
+			}
+			names = append([]string{current.GetCurrentConfigFull()}, names...)
+		}
+		from, to := names[0], names[1]
+
+		resolved, err := cfg.GetResolvedVars(from, to)
+		if err != nil {
+			return err
+		}
+		fromEntry, err := cfg.GetEntry(from)
+		if err != nil {
+			return err
+		}
+		toEntry, err := cfg.GetEntry(to)
+		if err != nil {
+			return err
+		}
+
+		fingerprintKey, err := config.LoadOrCreateFingerprintKey()
+		if err != nil {
+			return err
+		}
+		changes := core.DiffVars(resolved[from], resolved[to])
+		for i, c := range changes {
+			if fromEntry.IsSecret(c.Key) || toEntry.IsSecret(c.Key) {
+				changes[i].Old = maskValue(fingerprintKey, c.Old)
+				changes[i].New = maskValue(fingerprintKey, c.New)
+			}
+		}
+
+		switch diffFormatFlag {
+		case "json":
+			return printDiffJSON(from, to, changes)
+		case "unified":
+			printDiffUnified(from, to, changes)
+			return nil
+		case "text":
+			return printDiffText(from, to, changes)
+		default:
+			return fmt.Errorf(text.Text.Errors.UnknownFormat, diffFormatFlag)
+		}
+	},
+}
+
+// maskValue replaces a non-empty value with its fingerprint under key
+func maskValue(key []byte, value string) string {
+	if value == "" {
+		return ""
+	}
+	return fmt.Sprintf(text.Text.Formats.MaskedValue, config.Fingerprint(key, value))
+}
+
+// printDiffText prints changed keys only, one per line
+func printDiffText(from, to string, changes []core.Change) error {
+	fmt.Printf(text.Text.Formats.DiffHeader, from, to)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	changed := 0
+	for _, c := range changes {
+		switch c.Kind {
+		case core.ChangeAdded:
+			fmt.Fprintf(w, text.Text.Formats.DiffAdded, c.Key, c.New)
+		case core.ChangeRemoved:
+			fmt.Fprintf(w, text.Text.Formats.DiffRemoved, c.Key, c.Old)
+		case core.ChangeChanged:
+			fmt.Fprintf(w, text.Text.Formats.DiffChanged, c.Key, c.Old, c.New)
+		default:
+			continue
+		}
+		changed++
+	}
+	if changed == 0 {
+		fmt.Fprint(w, text.Text.Messages.NoDifferences)
+	}
+	return w.Flush()
+}
+
+// printDiffJSON prints changed keys as JSON
+func printDiffJSON(from, to string, changes []core.Change) error {
+	changed := []core.Change{}
+	for _, c := range changes {
+		if c.Kind != core.ChangeUnchanged {
+			changed = append(changed, c)
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		From    string        `json:"from"`
+		To      string        `json:"to"`
+		Changes []core.Change `json:"changes"`
+	}{from, to, changed})
+}
+
+// printDiffUnified prints both configurations as a single-hunk unified diff of
+// KEY="value" lines, with unchanged keys as context
+func printDiffUnified(from, to string, changes []core.Change) {
+	var lines []string
+	oldCount, newCount := 0, 0
+	for _, c := range changes {
+		switch c.Kind {
+		case core.ChangeUnchanged:
+			lines = append(lines, " "+formatAssignment(c.Key, c.Old))
+			oldCount++
+			newCount++
+		case core.ChangeAdded:
+			lines = append(lines, "+"+formatAssignment(c.Key, c.New))
+			newCount++
+		case core.ChangeRemoved:
+			lines = append(lines, "-"+formatAssignment(c.Key, c.Old))
+			oldCount++
+		case core.ChangeChanged:
+			lines = append(lines, "-"+formatAssignment(c.Key, c.Old), "+"+formatAssignment(c.Key, c.New))
+			oldCount++
+			newCount++
+		}
+	}
+
+	fmt.Printf(text.Text.Formats.UnifiedFrom, from)
+	fmt.Printf(text.Text.Formats.UnifiedTo, to)
+	fmt.Printf(text.Text.Formats.UnifiedHunk, hunkStart(oldCount), oldCount, hunkStart(newCount), newCount)
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
+
+// hunkStart returns the first line number of a hunk side; empty sides start at 0
+func hunkStart(count int) int {
+	if count == 0 {
+		return 0
+	}
+	return 1
+}
+
+// formatAssignment returns a KEY="value" line
+func formatAssignment(key, value string) string {
+	return fmt.Sprintf(text.Text.Formats.Assignment, key, value)
+}
+
+func init() {
+	diffCmd.Flags().StringVarP(&diffFormatFlag, "format", "f", "text", text.Text.Commands.Flags.DiffFormat)
+}
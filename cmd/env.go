@@ -4,10 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"envpick/internal/config"
 	"envpick/internal/core"
 	"envpick/internal/selector"
 	"envpick/internal/text"
@@ -24,16 +28,73 @@ var envCmd = &cobra.Command{
 			return
 		}
 
+		// Shells with a selection of their own (ep tmp) don't follow other terminals
+		if envFollowFlag && engine.SessionPinned() {
+			return
+		}
+
+		// Revert selections whose lease (envpick use --for) has expired, and
+		// unset their variables before exporting what is selected now
+		expired, err := engine.ExpireLeases()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, text.Text.Formats.ErrorPrefix, err)
+			return
+		}
+		if len(expired) > 0 {
+			// Tools outside the shell must not keep the leased variables either
+			syncActiveEnv()
+		}
+		var statements []string
+		for _, lease := range expired {
+			name := config.BuildConfigName(lease.Namespace, lease.Config)
+			if lease.Restored != "" {
+				fmt.Fprintf(os.Stderr, text.Text.Messages.LeaseExpiredRestored, name, config.BuildConfigName(lease.Namespace, lease.Restored))
+			} else {
+				fmt.Fprintf(os.Stderr, text.Text.Messages.LeaseExpired, name)
+			}
+			if keys := varNames(engine.GetConfig(), name); len(keys) > 0 {
+				statements = append(statements, fmt.Sprintf(text.Text.Formats.UnsetStatement, strings.Join(keys, " ")))
+			}
+		}
+
 		// Get current config (full name with namespace)
-		configName := engine.GetCurrentConfigFull()
+		var configNames, leaseNames []string
+		if envFollowFlag {
+			// Switch every namespace whose persisted selection changed since the
+			// shell last applied it, unsetting what the new selection doesn't set
+			changes := engine.ChangedSelections(strings.Fields(os.Getenv(selectionsVar)))
+			if len(changes) == 0 && len(expired) == 0 {
+				return
+			}
+			statements = nil
+			for _, change := range changes {
+				if keys := engine.StaleVars(change.From, change.To); len(keys) > 0 {
+					statements = append(statements, fmt.Sprintf(text.Text.Formats.UnsetStatement, strings.Join(keys, " ")))
+				}
+				if change.To != "" {
+					configNames = append(configNames, change.To)
+				}
+			}
+			leaseNames = engine.PersistedConfigsFull()
+		} else {
+			if allNamespacesFlag {
+				configNames = engine.GetAllCurrentConfigsFull()
+			} else if name := engine.GetCurrentConfigFull(); name != "" || len(expired) == 0 {
+				configNames = []string{name}
+			}
+			leaseNames = configNames
+		}
 
-		exports, err := engine.GetConfig().GetExportStatements(configName)
+		exports, err := engine.GetConfig().GetExportStatements(configNames...)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, text.Text.Formats.ErrorPrefix, err)
 			return
 		}
+		statements = append(statements, exports...)
+		statements = append(statements, leaseStatements(engine, leaseNames)...)
+		statements = append(statements, fmt.Sprintf(text.Text.Formats.ExportStatement, selectionsVar, appliedSelections(engine)))
 
-		fmt.Println(strings.Join(exports, "\n"))
+		fmt.Println(strings.Join(statements, "\n"))
 	},
 }
 
@@ -82,16 +143,118 @@ var envSelectCmd = &cobra.Command{
 			}
 		}
 
+		activation, err := confirmProtected(engine.GetConfig(), selected, actionTmp)
+		if err != nil {
+			return err
+		}
+
+		// Hooks write to stderr so that their output is not evaluated by the shell
+		if !noHooksFlag {
+			if err := engine.RunSwitchHooks(engine.GetCurrentConfigFull(), selected, os.Stderr); err != nil {
+				return err
+			}
+		}
+
 		exports, err := engine.GetConfig().GetExportStatements(selected)
 		if err != nil {
 			return err
 		}
+		recordActivation(activation)
 
 		fmt.Println(strings.Join(exports, "\n"))
 		return nil
 	},
 }
 
+var (
+	allNamespacesFlag bool
+	envFollowFlag     bool
+)
+
+// selectionsVar lists the persisted selection of every namespace (full names)
+// as last applied by a shell, so that 'envpick env --follow' only outputs the
+// namespaces that changed since
+const selectionsVar = "ENVPICK_SELECTIONS"
+
+// appliedSelections returns the value of selectionsVar for the output of
+// 'envpick env': the namespaces it applies (or, with --follow or on the
+// shell's first run, every namespace) take their persisted selection, the
+// others keep what the shell recorded, so that a later --follow applies them
+func appliedSelections(engine *core.Engine) string {
+	recorded, ok := os.LookupEnv(selectionsVar)
+	if envFollowFlag || allNamespacesFlag || !ok {
+		return strings.Join(engine.PersistedConfigsFull(), " ")
+	}
+
+	var selections []string
+	for _, name := range strings.Fields(recorded) {
+		if ns, _ := config.ParseConfigName(name); ns != engine.GetNamespace() {
+			selections = append(selections, name)
+		}
+	}
+	for _, name := range engine.PersistedConfigsFull() {
+		if ns, _ := config.ParseConfigName(name); ns == engine.GetNamespace() {
+			selections = append(selections, name)
+		}
+	}
+	sort.Strings(selections)
+	return strings.Join(selections, " ")
+}
+
+// Shell variables that let the precmd hook of 'envpick init' unset leased
+// configurations in shells that are already open
+const (
+	leaseExpiresVar = "ENVPICK_LEASE_EXPIRES" // earliest lease expiry, in Unix seconds
+	leaseKeysVar    = "ENVPICK_LEASE_KEYS"    // variables to unset when it passes
+)
+
+// leaseStatements exports the expiry and variables of leased configurations
+// among names, or clears them if none is leased
+func leaseStatements(engine *core.Engine, names []string) []string {
+	var (
+		expires time.Time
+		keys    []string
+	)
+	for _, name := range names {
+		ns, _ := config.ParseConfigName(name)
+		lease, ok := engine.GetLease(ns)
+		if !ok {
+			continue
+		}
+		if expires.IsZero() || lease.Expires.Before(expires) {
+			expires = lease.Expires
+		}
+		keys = append(keys, varNames(engine.GetConfig(), name)...)
+	}
+
+	if expires.IsZero() {
+		if os.Getenv(leaseExpiresVar) == "" {
+			return nil
+		}
+		return []string{fmt.Sprintf(text.Text.Formats.UnsetStatement, leaseExpiresVar+" "+leaseKeysVar)}
+	}
+	return []string{
+		fmt.Sprintf(text.Text.Formats.ExportStatement, leaseExpiresVar, strconv.FormatInt(expires.Unix(), 10)),
+		fmt.Sprintf(text.Text.Formats.ExportStatement, leaseKeysVar, strings.Join(keys, " ")),
+	}
+}
+
+// varNames returns the sorted variable names (not metadata) of a configuration
+func varNames(cfg *config.Config, name string) []string {
+	var keys []string
+	for k := range cfg.Configs[name] {
+		if !strings.HasPrefix(k, "_") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func init() {
+	envCmd.Flags().BoolVarP(&allNamespacesFlag, "all-namespaces", "A", false, text.Text.Commands.Flags.AllNamespaces)
+	envCmd.Flags().BoolVar(&envFollowFlag, "follow", false, text.Text.Commands.Flags.EnvFollow)
+	envSelectCmd.Flags().BoolVar(&noHooksFlag, "no-hooks", false, text.Text.Commands.Flags.NoHooks)
+	envSelectCmd.Flags().BoolVarP(&protectedYesFlag, "yes", "y", false, text.Text.Commands.Flags.ConfirmProtected)
 	envCmd.AddCommand(envSelectCmd)
 }
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"envpick/internal/config"
+	"envpick/internal/core"
+)
+
+const envTestConfig = `[dev]
+API_URL = "http://localhost"
+DEBUG = "1"
+
+[prod]
+API_URL = "https://api.example.com"
+
+[db.local]
+DATABASE_URL = "postgres://localhost"
+
+[db.staging]
+DATABASE_URL = "postgres://staging"
+`
+
+// setupEnvTest writes envTestConfig and the state that update makes, and
+// returns with no selections recorded by the calling shell
+func setupEnvTest(t *testing.T, update func(s *config.State)) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv(core.SessionVar, "")
+	t.Setenv(selectionsVar, "")
+	os.Unsetenv(selectionsVar)
+	t.Setenv(leaseExpiresVar, "")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".envpick"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".envpick", "config.toml"), []byte(envTestConfig), 0644))
+
+	state, err := config.LoadState()
+	require.NoError(t, err)
+	update(state)
+	require.NoError(t, state.Save())
+}
+
+// runEnv runs 'envpick env' with args and returns the statements it printed
+func runEnv(t *testing.T, args ...string) []string {
+	namespaceFlag = ""
+	envFollowFlag = false
+	allNamespacesFlag = false
+	defer func() {
+		namespaceFlag = ""
+		envFollowFlag = false
+		allNamespacesFlag = false
+	}()
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	rootCmd.SetArgs(append([]string{"env"}, args...))
+	execErr := rootCmd.Execute()
+	w.Close()
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, execErr)
+
+	var statements []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line != "" {
+			statements = append(statements, line)
+		}
+	}
+	return statements
+}
+
+func TestEnvFollowUnsetsStaleVars(t *testing.T) {
+	setupEnvTest(t, func(s *config.State) {
+		s.SetCurrentConfig("", "prod")
+	})
+	t.Setenv(selectionsVar, "dev")
+
+	assert.Equal(t, []string{
+		`unset DEBUG`,
+		`export API_URL="https://api.example.com"`,
+		`export ENVPICK_SELECTIONS="prod"`,
+	}, runEnv(t, "--follow"))
+}
+
+func TestEnvFollowUnchanged(t *testing.T) {
+	setupEnvTest(t, func(s *config.State) {
+		s.SetCurrentConfig("", "prod")
+		s.SetCurrentConfig("db", "local")
+	})
+	t.Setenv(selectionsVar, "db.local prod")
+
+	assert.Empty(t, runEnv(t, "--follow"), "nothing changed, so nothing is printed")
+}
+
+func TestEnvFollowClearedSelection(t *testing.T) {
+	setupEnvTest(t, func(s *config.State) {
+		s.SetCurrentConfig("", "dev")
+	})
+	t.Setenv(selectionsVar, "db.staging dev")
+
+	// Only the namespace that changed is touched
+	assert.Equal(t, []string{
+		`unset DATABASE_URL`,
+		`export ENVPICK_SELECTIONS="dev"`,
+	}, runEnv(t, "--follow"))
+}
+
+func TestEnvNamespaceMergesSelections(t *testing.T) {
+	setupEnvTest(t, func(s *config.State) {
+		s.SetCurrentConfig("", "prod")
+		s.SetCurrentConfig("db", "staging")
+	})
+	// The shell applied dev and db.local; 'envpick env -n db' only refreshes db
+	t.Setenv(selectionsVar, "db.local dev")
+
+	assert.Equal(t, []string{
+		`export DATABASE_URL="postgres://staging"`,
+		`export ENVPICK_SELECTIONS="db.staging dev"`,
+	}, runEnv(t, "-n", "db"))
+}
+
+func TestEnvNamespaceWithoutRecordedSelections(t *testing.T) {
+	setupEnvTest(t, func(s *config.State) {
+		s.SetCurrentConfig("", "prod")
+		s.SetCurrentConfig("db", "staging")
+	})
+
+	// A shell without ENVPICK_SELECTIONS records every persisted selection
+	assert.Equal(t, []string{
+		`export DATABASE_URL="postgres://staging"`,
+		`export ENVPICK_SELECTIONS="prod db.staging"`,
+	}, runEnv(t, "-n", "db"))
+}
+
+func TestEnvFollowExpiredLease(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		expected []string
+	}{
+		{
+			name:     "restored",
+			previous: "dev",
+			expected: []string{
+				`export API_URL="http://localhost"`,
+				`export DEBUG="1"`,
+				`unset ENVPICK_LEASE_EXPIRES ENVPICK_LEASE_KEYS`,
+				`export ENVPICK_SELECTIONS="dev"`,
+			},
+		},
+		{
+			name:     "cleared",
+			previous: "",
+			expected: []string{
+				`unset API_URL`,
+				`unset ENVPICK_LEASE_EXPIRES ENVPICK_LEASE_KEYS`,
+				`export ENVPICK_SELECTIONS=""`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupEnvTest(t, func(s *config.State) {
+				s.SetCurrentConfig("", "prod")
+				s.SetLease("", tt.previous, time.Now().Add(-time.Minute))
+			})
+			t.Setenv(selectionsVar, "prod")
+			t.Setenv(leaseExpiresVar, "1")
+
+			assert.Equal(t, tt.expected, runEnv(t, "--follow"))
+
+			state, err := config.LoadState()
+			require.NoError(t, err)
+			assert.Equal(t, tt.previous, state.GetCurrentConfig(""))
+			_, leased := state.GetLease("")
+			assert.False(t, leased, "the expired lease should be removed")
+		})
+	}
+}
+
+func TestEnvFollowActiveLease(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
+	setupEnvTest(t, func(s *config.State) {
+		s.SetCurrentConfig("", "prod")
+		s.SetLease("", "dev", expires)
+	})
+	t.Setenv(selectionsVar, "dev")
+
+	statements := runEnv(t, "--follow")
+	assert.Contains(t, statements, `unset DEBUG`)
+	assert.Contains(t, statements, `export ENVPICK_LEASE_KEYS="API_URL"`)
+	assert.Contains(t, statements, `export ENVPICK_SELECTIONS="prod"`)
+}
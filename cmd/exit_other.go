@@ -0,0 +1,10 @@
+//go:build !unix
+
+package cmd
+
+import "os/exec"
+
+// exitStatus returns the exit code of the command
+func exitStatus(err *exec.ExitError) int {
+	return err.ExitCode()
+}
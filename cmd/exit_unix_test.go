@@ -0,0 +1,33 @@
+//go:build unix
+
+package cmd
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		expected int
+	}{
+		{name: "exit code", script: "exit 3", expected: 3},
+		{name: "killed by SIGTERM", script: "kill -TERM $$", expected: 143},
+		{name: "killed by SIGKILL", script: "kill -KILL $$", expected: 137},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := exec.Command("sh", "-c", tt.script).Run()
+			var exitErr *exec.ExitError
+			require.True(t, errors.As(err, &exitErr), "command should fail")
+			assert.Equal(t, tt.expected, exitStatus(exitErr))
+		})
+	}
+}
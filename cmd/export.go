@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/core"
+	"envpick/internal/export"
+	"envpick/internal/text"
+)
+
+var (
+	exportFormatFlag string
+	exportOutputFlag string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   text.Text.Commands.Export.Use,
+	Short: text.Text.Commands.Export.Short,
+	Long:  text.Text.Commands.Export.Long,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngineWithNamespace(namespaceFlag)
+		if err != nil {
+			return err
+		}
+
+		var name string
+		if len(args) > 0 {
+			name = args[0]
+		}
+		fullName, err := engine.GetConfigFull(name)
+		if err != nil {
+			return err
+		}
+
+		cfg := engine.GetConfig()
+		entry, err := cfg.GetEntry(fullName)
+		if err != nil {
+			return err
+		}
+		resolved, err := cfg.GetResolvedVars(fullName)
+		if err != nil {
+			return err
+		}
+
+		profile := export.Profile{
+			Name:    fullName,
+			Vars:    resolved[fullName],
+			Secrets: make(map[string]bool),
+		}
+		for k := range profile.Vars {
+			profile.Secrets[k] = entry.IsSecret(k)
+		}
+
+		data, err := export.Render(exportFormatFlag, profile)
+		if err != nil {
+			return err
+		}
+
+		if exportOutputFlag == "" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		if err := os.WriteFile(exportOutputFlag, data, 0600); err != nil {
+			return fmt.Errorf(text.Text.Errors.ExportWrite, err)
+		}
+		fmt.Fprintf(os.Stderr, text.Text.Messages.ExportedConfig, fullName, exportOutputFlag)
+		return nil
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFormatFlag, "format", "f", "dotenv", text.Text.Commands.Flags.ExportFormat)
+	exportCmd.Flags().StringVarP(&exportOutputFlag, "output", "o", "", text.Text.Commands.Flags.ExportOutput)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/core"
+	"envpick/internal/text"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   text.Text.Commands.History.Use,
+	Short: text.Text.Commands.History.Short,
+	Long:  text.Text.Commands.History.Long,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngineWithNamespace(namespaceFlag)
+		if err != nil {
+			return err
+		}
+
+		history := engine.GetHistory()
+		if len(history) == 0 {
+			fmt.Print(text.Text.Messages.NoHistory)
+			return nil
+		}
+
+		// Most recent first
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprint(w, text.Text.Formats.HistoryTableHeader)
+		for i := len(history) - 1; i >= 0; i-- {
+			entry := history[i]
+			fmt.Fprintf(w, text.Text.Formats.HistoryTableRow, entry.Time.Local().Format(text.Text.Formats.HistoryTime), entry.Config)
+		}
+		return w.Flush()
+	},
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/bundle"
+	"envpick/internal/config"
+	"envpick/internal/text"
+)
+
+var identityCmd = &cobra.Command{
+	Use:   text.Text.Commands.Identity.Use,
+	Short: text.Text.Commands.Identity.Short,
+	Long:  text.Text.Commands.Identity.Long,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		identity, err := config.LoadOrCreateIdentity()
+		if err != nil {
+			return err
+		}
+
+		signingKey, err := config.LoadOrCreateSigningKey()
+		if err != nil {
+			return err
+		}
+		publicKey := config.EncodePublicKey(signingKey.Public().(ed25519.PublicKey))
+
+		fmt.Printf(text.Text.Messages.IdentityRecipient, identity.Recipient().String())
+		fmt.Printf(text.Text.Messages.IdentitySigner, publicKey, bundle.Fingerprint(publicKey))
+		return nil
+	},
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/config"
+	"envpick/internal/dotenv"
+	"envpick/internal/text"
+	"envpick/internal/tomledit"
+)
+
+// Import policies for keys that already exist in the target configuration
+const (
+	importMerge     = "merge"     // file values replace existing ones, other keys are kept
+	importKeep      = "keep"      // existing values are kept, only new keys are added
+	importOverwrite = "overwrite" // variables not in the file are removed, metadata is kept
+)
+
+var (
+	importAsFlag          string
+	importPolicyFlag      string
+	importMarkSecretsFlag bool
+	importYesFlag         bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   text.Text.Commands.Import.Use,
+	Short: text.Text.Commands.Import.Short,
+	Long:  text.Text.Commands.Import.Long,
+}
+
+var importDotenvCmd = &cobra.Command{
+	Use:   text.Text.Commands.ImportDotenv.Use,
+	Short: text.Text.Commands.ImportDotenv.Short,
+	Long:  text.Text.Commands.ImportDotenv.Long,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch importPolicyFlag {
+		case importMerge, importKeep, importOverwrite:
+		default:
+			return fmt.Errorf(text.Text.Errors.UnknownImportPolicy, importPolicyFlag)
+		}
+
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf(text.Text.Errors.DotenvRead, err)
+		}
+		entries, err := dotenv.Parse(data)
+		if err != nil {
+			return fmt.Errorf(text.Text.Errors.DotenvParse, args[0], err)
+		}
+		if len(entries) == 0 {
+			return fmt.Errorf(text.Text.Errors.DotenvEmpty, args[0])
+		}
+		for _, e := range entries {
+			if !validKey.MatchString(e.Key) || strings.HasPrefix(e.Key, "_") {
+				return fmt.Errorf(text.Text.Errors.InvalidKey, e.Key)
+			}
+		}
+
+		// A missing config file simply means this is the first configuration
+		cfg, err := config.LoadConfigOrEmpty()
+		if err != nil {
+			return err
+		}
+
+		target := config.BuildConfigName(namespaceFlag, importAsFlag)
+		existing, exists := cfg.Configs[target]
+		if !exists {
+			if err := checkNewName(cfg, target); err != nil {
+				return err
+			}
+		}
+
+		updated, removed := importValues(existing, cfg.Sources[target], entries)
+		if importMarkSecretsFlag {
+			markSecrets(updated)
+		}
+
+		if !exists {
+			fmt.Printf(text.Text.Messages.ImportNewProfile, target)
+		}
+		printKeyChanges(existing, updated)
+		if exists && len(removed) == 0 && maps.Equal(existing, updated) {
+			fmt.Printf(text.Text.Messages.ImportNoChanges, target)
+			return nil
+		}
+
+		if !importYesFlag {
+			ok, err := confirm(fmt.Sprintf(text.Text.Prompts.ConfirmWriteProfile, target))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errors.New(text.Text.Errors.Aborted)
+			}
+		}
+
+		// Keys in file order, then metadata
+		var keys []string
+		for _, e := range entries {
+			keys = append(keys, e.Key)
+		}
+		if _, ok := updated["_secret_keys"]; ok {
+			keys = append(keys, "_secret_keys")
+		}
+
+		err = config.UpdateConfigFile(func(doc *tomledit.Document) error {
+			if !exists {
+				values := make([]tomledit.KeyValue, 0, len(keys))
+				for _, k := range keys {
+					values = append(values, tomledit.KeyValue{Key: k, Value: updated[k]})
+				}
+				doc.AppendTable(target, values)
+				return nil
+			}
+
+			if !doc.HasTable(target) {
+				return fmt.Errorf(text.Text.Errors.ConfigTableNotEditable, target)
+			}
+			for _, k := range removed {
+				doc.DeleteValue(target, k)
+			}
+			for _, k := range keys {
+				if existing[k] != updated[k] {
+					doc.SetValue(target, k, updated[k])
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf(text.Text.Messages.WroteProfile, target)
+		return nil
+	},
+}
+
+// importValues applies the import policy to the existing values of a
+// configuration, returning the resulting values and the keys to remove.
+// sources gives the layer of each existing key: values of the team config
+// can't be removed from config.toml, so they are kept.
+func importValues(existing, sources map[string]string, entries []dotenv.Entry) (map[string]string, []string) {
+	updated := make(map[string]string, len(existing)+len(entries))
+	for k, v := range existing {
+		updated[k] = v
+	}
+
+	imported := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		imported[e.Key] = true
+		if _, ok := existing[e.Key]; ok && importPolicyFlag == importKeep {
+			continue
+		}
+		updated[e.Key] = e.Value
+	}
+
+	var removed []string
+	if importPolicyFlag == importOverwrite {
+		for k := range existing {
+			if !imported[k] && !strings.HasPrefix(k, "_") && sources[k] != config.LayerTeam {
+				delete(updated, k)
+				removed = append(removed, k)
+			}
+		}
+		sort.Strings(removed)
+	}
+	return updated, removed
+}
+
+// markSecrets adds variables that look like secrets, but are not masked by
+// their name alone, to _secret_keys
+func markSecrets(vars map[string]string) {
+	marked := make(map[string]bool)
+	for _, k := range strings.Split(vars["_secret_keys"], ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			marked[k] = true
+		}
+	}
+
+	added := false
+	for k, v := range vars {
+		if strings.HasPrefix(k, "_") || marked[k] || config.IsSecretKeyName(k) {
+			continue
+		}
+		if config.LooksSecret(k, v) {
+			marked[k] = true
+			added = true
+		}
+	}
+	if !added {
+		return
+	}
+
+	keys := make([]string, 0, len(marked))
+	for k := range marked {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	vars["_secret_keys"] = strings.Join(keys, ", ")
+}
+
+func init() {
+	importDotenvCmd.Flags().StringVar(&importAsFlag, "as", "", text.Text.Commands.Flags.ImportAs)
+	importDotenvCmd.Flags().StringVar(&importPolicyFlag, "policy", importMerge, text.Text.Commands.Flags.ImportPolicy)
+	importDotenvCmd.Flags().BoolVar(&importMarkSecretsFlag, "mark-secrets", false, text.Text.Commands.Flags.MarkSecrets)
+	importDotenvCmd.Flags().BoolVarP(&importYesFlag, "yes", "y", false, text.Text.Commands.Flags.Yes)
+	_ = importDotenvCmd.MarkFlagRequired("as")
+	importCmd.AddCommand(importDotenvCmd)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"envpick/internal/config"
+	"envpick/internal/dotenv"
+)
+
+func TestImportValuesOverwriteKeepsTeamKeys(t *testing.T) {
+	importPolicyFlag = importOverwrite
+	defer func() { importPolicyFlag = importMerge }()
+
+	existing := map[string]string{"BASE_URL": "team", "MODEL": "mine", "OLD": "x", "_web_url": "u"}
+	sources := map[string]string{
+		"BASE_URL": config.LayerTeam,
+		"MODEL":    config.LayerPersonal,
+		"OLD":      config.LayerPersonal,
+		"_web_url": config.LayerPersonal,
+	}
+	entries := []dotenv.Entry{{Key: "MODEL", Value: "new"}}
+
+	updated, removed := importValues(existing, sources, entries)
+	assert.Equal(t, []string{"OLD"}, removed, "team keys can't be removed from config.toml")
+	assert.Equal(t, map[string]string{"BASE_URL": "team", "MODEL": "new", "_web_url": "u"}, updated)
+}
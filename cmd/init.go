@@ -21,9 +21,14 @@ var initZshCmd = &cobra.Command{
 	Long:  text.Text.Commands.InitZsh.Long,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Print(zshConfig)
+		if initFollowFlag {
+			fmt.Print(zshFollowConfig)
+		}
 	},
 }
 
+var initFollowFlag bool
+
 const zshConfig = `# envpick shell integration
 if command -v envpick >/dev/null 2>&1; then
     # Load shell completion if completion system is initialized
@@ -31,23 +36,71 @@ if command -v envpick >/dev/null 2>&1; then
         source <(envpick completion zsh)
     fi
 
-    # Load persisted environment on shell startup
-    eval "$(envpick env 2>/dev/null)"
+    # Terminal session for 'ep tmp': kept across 'exec zsh' (same PID), new in
+    # any other shell, including ones started from this one
+    if [[ "${ENVPICK_SESSION%%-*}" != "$$" ]]; then
+        export ENVPICK_SESSION="$$-$RANDOM$RANDOM"
+    fi
+
+    # Load persisted environment on shell startup, except in 'envpick shell'
+    # subshells, which keep the configuration they were started with
+    if [[ -z $ENVPICK_SHELL ]]; then
+        eval "$(envpick env 2>/dev/null)"
+    fi
+
+    # Unset leased variables (envpick use --for) once the lease has expired
+    zmodload zsh/datetime
+    autoload -Uz add-zsh-hook
+    _envpick_lease_check() {
+        [[ -z $ENVPICK_SHELL ]] || return
+        if [[ -n "$ENVPICK_LEASE_EXPIRES" ]] && (( EPOCHSECONDS >= ENVPICK_LEASE_EXPIRES )); then
+            unset ${=ENVPICK_LEASE_KEYS} ENVPICK_LEASE_EXPIRES ENVPICK_LEASE_KEYS
+            eval "$(envpick env)"
+        fi
+    }
+    add-zsh-hook precmd _envpick_lease_check
+
+    # Sets reply to the namespace option among the arguments of 'ep use' or
+    # 'ep tmp', so that 'envpick env' applies the namespace that was switched
+    _envpick_namespace() {
+        reply=()
+        while (( $# )); do
+            case "$1" in
+                -n|--namespace) reply=(-n "$2"); shift ;;
+                --namespace=*) reply=(-n "${1#--namespace=}") ;;
+                -n?*) reply=(-n "${1#-n}") ;;
+            esac
+            shift
+        done
+    }
 
     # Helper function for envpick operations
     ep() {
+        local -a reply
         case "$1" in
             use)
                 # Interactive selection with persistence
                 shift
                 if envpick use "$@"; then
-                    eval "$(envpick env)"
+                    _envpick_namespace "$@"
+                    eval "$(envpick env "${reply[@]}")"
                 fi
                 ;;
             tmp)
-                # Temporary selection (no persistence)
+                # Selection for this terminal only; without a session (shells
+                # set up by an older envpick) it is applied but not kept
                 shift
-                eval "$(envpick env select "$@")"
+                if [[ -z $ENVPICK_SESSION ]]; then
+                    eval "$(envpick env select "$@")"
+                elif envpick use --session "$@"; then
+                    _envpick_namespace "$@"
+                    eval "$(envpick env "${reply[@]}")"
+                fi
+                ;;
+            off)
+                # Clear the selection and unset its variables
+                shift
+                eval "$(envpick off "$@")"
                 ;;
             *)
                 # Pass through all other commands
@@ -58,6 +111,32 @@ if command -v envpick >/dev/null 2>&1; then
 fi
 `
 
+// zshFollowConfig re-applies 'envpick env' before a prompt once state.toml
+// has been replaced, which every write does; zstat is a builtin, so prompts
+// without a change don't start a process
+const zshFollowConfig = `
+# Follow persisted switches made in other terminals
+if command -v envpick >/dev/null 2>&1; then
+    zmodload -F zsh/stat b:zstat
+    typeset -gA _envpick_state
+    _envpick_state_changed() {
+        local -A st
+        zstat -H st -- "$HOME/.envpick/state.toml" 2>/dev/null || return 1
+        [[ "$st[inode]:$st[mtime]" != "$_envpick_state[stamp]" ]] || return 1
+        _envpick_state[stamp]="$st[inode]:$st[mtime]"
+    }
+    _envpick_follow() {
+        [[ -z $ENVPICK_SHELL ]] || return
+        if _envpick_state_changed; then
+            eval "$(envpick env --follow)"
+        fi
+    }
+    _envpick_state_changed
+    add-zsh-hook precmd _envpick_follow
+fi
+`
+
 func init() {
+	initZshCmd.Flags().BoolVar(&initFollowFlag, "follow", false, text.Text.Commands.Flags.InitFollow)
 	initCmd.AddCommand(initZshCmd)
 }
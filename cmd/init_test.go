@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZshConfigSkipsEnvpickShell(t *testing.T) {
+	// 'envpick shell' subshells keep their configuration: neither the startup
+	// eval nor the precmd hooks may re-apply the persisted selection
+	const guard = "[[ -z $ENVPICK_SHELL ]]"
+
+	assert.Contains(t, zshConfig, "if "+guard+"; then\n        eval \"$(envpick env 2>/dev/null)\"")
+	assert.Contains(t, zshConfig, "_envpick_lease_check() {\n        "+guard+" || return")
+	assert.Contains(t, zshFollowConfig, "_envpick_follow() {\n        "+guard+" || return")
+	assert.Equal(t, 3, strings.Count(zshConfig+zshFollowConfig, guard))
+}
+
+func TestZshConfigTmpWithoutSession(t *testing.T) {
+	// 'ep tmp' falls back to a selection that is not kept when the shell has no session
+	assert.Contains(t, zshConfig, `if [[ -z $ENVPICK_SESSION ]]; then
+                    eval "$(envpick env select "$@")"
+                elif envpick use --session "$@"; then`)
+}
+
+func TestZshConfigForwardsNamespace(t *testing.T) {
+	// 'ep use' and 'ep tmp' apply the namespace they switched, not the default one
+	assert.Equal(t, 2, strings.Count(zshConfig, `_envpick_namespace "$@"
+                    eval "$(envpick env "${reply[@]}")"`))
+
+	// The helper only uses syntax that bash shares with zsh, so run it there
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+	start := strings.Index(zshConfig, "_envpick_namespace() {")
+	end := strings.Index(zshConfig[start:], "\n    }\n") + len("\n    }\n")
+	require.Positive(t, start)
+	function := zshConfig[start : start+end]
+
+	tests := []struct {
+		args     string
+		expected string
+	}{
+		{args: "-n db prod", expected: "-n db"},
+		{args: "prod --namespace db", expected: "-n db"},
+		{args: "--namespace=db prod", expected: "-n db"},
+		{args: "-ndb prod", expected: "-n db"},
+		{args: "--yes prod", expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			script := function + "\n_envpick_namespace " + tt.args + "\nprintf '%s ' \"${reply[@]}\""
+			out, err := exec.Command(bash, "-c", script).Output()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, strings.TrimSpace(string(out)))
+		})
+	}
+}
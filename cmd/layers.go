@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/config"
+	"envpick/internal/text"
+)
+
+var layersCmd = &cobra.Command{
+	Use:   text.Text.Commands.Layers.Use,
+	Short: text.Text.Commands.Layers.Short,
+	Long:  text.Text.Commands.Layers.Long,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		teamPath, _ := config.GetTeamConfigPath()
+		if hasTeamLayer(cfg) {
+			fmt.Printf(text.Text.Messages.TeamLayerVerified, teamPath)
+		} else {
+			fmt.Print(text.Text.Messages.TeamLayerNone)
+		}
+
+		var names []string
+		if len(args) > 0 {
+			fullName := config.BuildConfigName(namespaceFlag, args[0])
+			if _, ok := cfg.Configs[fullName]; !ok {
+				return fmt.Errorf(text.Text.Errors.ConfigNotFound, args[0])
+			}
+			names = []string{fullName}
+		} else {
+			for name := range cfg.Configs {
+				if ns, _ := config.ParseConfigName(name); namespaceFlag == "" || ns == namespaceFlag {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, name := range names {
+			fmt.Fprintf(w, text.Text.Formats.LayersConfig, name)
+
+			keys := make([]string, 0, len(cfg.Sources[name]))
+			for k := range cfg.Sources[name] {
+				keys = append(keys, k)
+			}
+			sortVarsFirst(keys)
+
+			for _, k := range keys {
+				fmt.Fprintf(w, text.Text.Formats.LayersKey, k, cfg.Sources[name][k])
+			}
+			for _, k := range cfg.Rejected[name] {
+				fmt.Fprintf(w, text.Text.Formats.LayersRejected, k)
+			}
+		}
+		return w.Flush()
+	},
+}
+
+var layersSignCmd = &cobra.Command{
+	Use:   text.Text.Commands.LayersSign.Use,
+	Short: text.Text.Commands.LayersSign.Short,
+	Long:  text.Text.Commands.LayersSign.Long,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signingKey, err := config.LoadOrCreateSigningKey()
+		if err != nil {
+			return err
+		}
+
+		sigPath, err := config.SignTeamConfig(args[0], signingKey)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf(text.Text.Messages.TeamConfigSigned, args[0], sigPath)
+		fmt.Printf(text.Text.Messages.TeamConfigTrustHint, config.EncodePublicKey(signingKey.Public().(ed25519.PublicKey)))
+		return nil
+	},
+}
+
+// hasTeamLayer reports whether any value was loaded from the team layer
+func hasTeamLayer(cfg *config.Config) bool {
+	for _, sources := range cfg.Sources {
+		for _, layer := range sources {
+			if layer == config.LayerTeam {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func init() {
+	layersCmd.AddCommand(layersSignCmd)
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/core"
+	"envpick/internal/text"
+)
+
+var (
+	listAllFlag    bool
+	listTreeFlag   bool
+	listFormatFlag string
+)
+
+var listCmd = &cobra.Command{
+	Use:   text.Text.Commands.List.Use,
+	Short: text.Text.Commands.List.Short,
+	Long:  text.Text.Commands.List.Long,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngineWithNamespace(namespaceFlag)
+		if err != nil {
+			return err
+		}
+
+		// A tree only makes sense across namespaces
+		infos := engine.ListConfigs(listAllFlag || listTreeFlag)
+
+		if listTreeFlag {
+			printTree(infos)
+			return nil
+		}
+
+		switch listFormatFlag {
+		case "names":
+			for _, info := range infos {
+				if listAllFlag {
+					fmt.Println(info.Name)
+				} else {
+					fmt.Println(info.Config)
+				}
+			}
+		case "json":
+			if infos == nil {
+				infos = []core.ConfigInfo{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(infos)
+		case "table":
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprint(w, text.Text.Formats.ListTableHeader)
+			for _, info := range infos {
+				fmt.Fprintf(w, text.Text.Formats.ListTableRow, displayNamespace(info.Namespace), info.Config, activeMarker(info.Active))
+			}
+			return w.Flush()
+		default:
+			return fmt.Errorf(text.Text.Errors.UnknownFormat, listFormatFlag)
+		}
+		return nil
+	},
+}
+
+// treeNode is a segment of a dotted configuration name
+type treeNode struct {
+	children map[string]*treeNode
+	config   bool // a configuration ends at this node
+	active   bool
+}
+
+// printTree prints configurations as a hierarchy of their dotted names
+func printTree(infos []core.ConfigInfo) {
+	root := &treeNode{children: make(map[string]*treeNode)}
+	for _, info := range infos {
+		node := root
+		for _, part := range strings.Split(info.Name, ".") {
+			child, ok := node.children[part]
+			if !ok {
+				child = &treeNode{children: make(map[string]*treeNode)}
+				node.children[part] = child
+			}
+			node = child
+		}
+		node.config = true
+		node.active = info.Active
+	}
+
+	for _, name := range sortedChildren(root) {
+		child := root.children[name]
+		fmt.Println(name + treeSuffix(child))
+		printTreeChildren(child, "")
+	}
+}
+
+// printTreeChildren prints the children of node with box-drawing prefixes
+func printTreeChildren(node *treeNode, prefix string) {
+	names := sortedChildren(node)
+	for i, name := range names {
+		child := node.children[name]
+		branch, indent := text.Text.Formats.TreeBranch, text.Text.Formats.TreeIndent
+		if i == len(names)-1 {
+			branch, indent = text.Text.Formats.TreeLastBranch, text.Text.Formats.TreeLastIndent
+		}
+		fmt.Println(prefix + branch + name + treeSuffix(child))
+		printTreeChildren(child, prefix+indent)
+	}
+}
+
+// sortedChildren returns the child names of node, sorted
+func sortedChildren(node *treeNode) []string {
+	names := make([]string, 0, len(node.children))
+	for name := range node.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// treeSuffix marks active configurations in the tree
+func treeSuffix(node *treeNode) string {
+	if node.active {
+		return text.Text.Formats.ActiveIndicator
+	}
+	return ""
+}
+
+// displayNamespace returns a printable namespace name
+func displayNamespace(namespace string) string {
+	if namespace == "" {
+		return text.Text.Formats.DefaultNamespace
+	}
+	return namespace
+}
+
+// activeMarker returns the table marker for an active configuration
+func activeMarker(active bool) string {
+	if active {
+		return text.Text.Formats.ActiveMarker
+	}
+	return ""
+}
+
+func init() {
+	listCmd.Flags().BoolVarP(&listAllFlag, "all", "a", false, text.Text.Commands.Flags.ListAll)
+	listCmd.Flags().BoolVar(&listTreeFlag, "tree", false, text.Text.Commands.Flags.ListTree)
+	listCmd.Flags().StringVarP(&listFormatFlag, "format", "f", "table", text.Text.Commands.Flags.ListFormat)
+}
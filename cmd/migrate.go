@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/config"
+	"envpick/internal/text"
+	"envpick/internal/tomledit"
+)
+
+var migrateDryRunFlag bool
+
+// migrateFile is a file upgraded by 'envpick migrate'
+type migrateFile struct {
+	path    func() (string, error)
+	migrate func(data []byte) ([]byte, []config.MigrationStep, error)
+	write   func() error
+}
+
+var migrateFiles = []migrateFile{
+	{
+		path:    config.GetConfigPath,
+		migrate: config.MigrateConfig,
+		write: func() error {
+			return config.UpdateConfigFile(func(doc *tomledit.Document) error {
+				_, err := config.MigrateConfigDocument(doc)
+				return err
+			})
+		},
+	},
+	{
+		path:    config.GetStatePath,
+		migrate: config.MigrateState,
+		// Loading upgrades the state; saving writes the current format
+		write: func() error {
+			_, err := config.UpdateState(func(*config.State) error { return nil })
+			return err
+		},
+	},
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   text.Text.Commands.Migrate.Use,
+	Short: text.Text.Commands.Migrate.Short,
+	Long:  text.Text.Commands.Migrate.Long,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, f := range migrateFiles {
+			path, err := f.path()
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			migrated, steps, err := f.migrate(data)
+			if err != nil {
+				return fmt.Errorf(text.Text.Errors.MigrateFailed, path, err)
+			}
+			if len(steps) == 0 {
+				fmt.Printf(text.Text.Messages.MigrateUpToDate, path)
+				continue
+			}
+
+			if migrateDryRunFlag {
+				fmt.Printf(text.Text.Messages.MigratePending, path)
+			} else {
+				if err := f.write(); err != nil {
+					return fmt.Errorf(text.Text.Errors.MigrateFailed, path, err)
+				}
+				fmt.Printf(text.Text.Messages.Migrated, path)
+			}
+			for _, step := range steps {
+				fmt.Printf(text.Text.Formats.MigrationStep, step.Version, step.Description)
+			}
+			if migrateDryRunFlag {
+				printFileDiff(path, data, migrated)
+			}
+		}
+		return nil
+	},
+}
+
+// printFileDiff prints the change from before to after as a single-hunk unified diff
+func printFileDiff(path string, before, after []byte) {
+	oldLines := splitLines(before)
+	newLines := splitLines(after)
+
+	fmt.Printf(text.Text.Formats.UnifiedFrom, path)
+	fmt.Printf(text.Text.Formats.UnifiedTo, path)
+	fmt.Printf(text.Text.Formats.UnifiedHunk, hunkStart(len(oldLines)), len(oldLines), hunkStart(len(newLines)), len(newLines))
+	for _, line := range lineDiff(oldLines, newLines) {
+		fmt.Println(line)
+	}
+}
+
+// splitLines splits data into lines without their terminators
+func splitLines(data []byte) []string {
+	s := strings.TrimSuffix(string(data), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// lineDiff returns the lines of a and b prefixed with " ", "-" or "+", using
+// their longest common subsequence as context
+func lineDiff(a, b []string) []string {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, " "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "-"+a[i])
+			i++
+		default:
+			lines = append(lines, "+"+b[j])
+			j++
+		}
+	}
+	return lines
+}
+
+func init() {
+	migrateCmd.Flags().BoolVar(&migrateDryRunFlag, "dry-run", false, text.Text.Commands.Flags.MigrateDryRun)
+}
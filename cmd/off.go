@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/config"
+	"envpick/internal/core"
+	"envpick/internal/text"
+)
+
+var offAllFlag bool
+
+var offCmd = &cobra.Command{
+	Use:   text.Text.Commands.Off.Use,
+	Short: text.Text.Commands.Off.Short,
+	Long:  text.Text.Commands.Off.Long,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngineWithNamespace(namespaceFlag)
+		if err != nil {
+			return err
+		}
+
+		names := []string{engine.GetCurrentConfigFull()}
+		if offAllFlag {
+			names = engine.GetAllCurrentConfigsFull()
+		}
+		leased := false
+		for _, name := range names {
+			ns, _ := config.ParseConfigName(name)
+			if _, ok := engine.GetLease(ns); ok {
+				leased = true
+			}
+		}
+
+		cleared, err := engine.ClearCurrentConfig(offAllFlag)
+		if err != nil {
+			return err
+		}
+		if len(cleared) == 0 {
+			fmt.Fprint(os.Stderr, text.Text.Messages.NothingToDeactivate)
+			return nil
+		}
+		syncActiveEnv()
+
+		// The selection is cleared first: a failing hook must not keep a
+		// leaked configuration active, so failures are only warnings here.
+		// Hooks write to stderr so that their output is not evaluated by the shell
+		if !noHooksFlag {
+			for _, name := range cleared {
+				if err := engine.RunSwitchHooks(name, "", os.Stderr); err != nil {
+					fmt.Fprintf(os.Stderr, text.Text.Messages.HookWarning, err)
+				}
+			}
+		}
+
+		var statements []string
+		for _, name := range cleared {
+			if keys := varNames(engine.GetConfig(), name); len(keys) > 0 {
+				statements = append(statements, fmt.Sprintf(text.Text.Formats.UnsetStatement, strings.Join(keys, " ")))
+			}
+			fmt.Fprintf(os.Stderr, text.Text.Messages.DeactivatedConfig, name)
+		}
+		if leased && os.Getenv(leaseExpiresVar) != "" {
+			statements = append(statements, fmt.Sprintf(text.Text.Formats.UnsetStatement, leaseExpiresVar+" "+leaseKeysVar))
+		}
+
+		if len(statements) > 0 {
+			fmt.Println(strings.Join(statements, "\n"))
+		}
+		return nil
+	},
+}
+
+func init() {
+	offCmd.Flags().BoolVar(&offAllFlag, "all", false, text.Text.Commands.Flags.OffAll)
+	offCmd.Flags().BoolVar(&noHooksFlag, "no-hooks", false, text.Text.Commands.Flags.NoHooks)
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"envpick/internal/core"
+)
+
+func TestOffClearsDespiteFailingHook(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".envpick"), 0755))
+	config := "[prod]\nAPI_KEY = \"leaked\"\n_on_deactivate = \"exit 1\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".envpick", "config.toml"), []byte(config), 0644))
+
+	namespaceFlag = ""
+	rootCmd.SetArgs([]string{"use", "prod"})
+	require.NoError(t, rootCmd.Execute())
+
+	// 'envpick off' is the kill switch: the abort policy of the hook must not stop it
+	rootCmd.SetArgs([]string{"off"})
+	require.NoError(t, rootCmd.Execute(), "off should succeed despite the failing hook")
+
+	engine, err := core.NewEngine()
+	require.NoError(t, err)
+	assert.Empty(t, engine.GetCurrentConfigFull(), "the selection should be cleared")
+}
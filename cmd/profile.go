@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/config"
+	"envpick/internal/core"
+	"envpick/internal/text"
+	"envpick/internal/tomledit"
+)
+
+var (
+	newFromFlag string
+	rmYesFlag   bool
+)
+
+// validName matches configuration names that can be written to config.toml:
+// a name, optionally prefixed by a namespace
+var validName = regexp.MustCompile(`^[A-Za-z0-9-][A-Za-z0-9_-]*(\.[A-Za-z0-9-][A-Za-z0-9_-]*)?$`)
+
+var newCmd = &cobra.Command{
+	Use:   text.Text.Commands.New.Use,
+	Short: text.Text.Commands.New.Short,
+	Long:  text.Text.Commands.New.Long,
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// A missing config file simply means this is the first configuration
+		cfg, err := config.LoadConfigOrEmpty()
+		if err != nil {
+			return err
+		}
+
+		target := config.BuildConfigName(namespaceFlag, args[0])
+		if err := checkNewName(cfg, target); err != nil {
+			return err
+		}
+
+		var values []tomledit.KeyValue
+		for _, arg := range args[1:] {
+			key, value, ok := strings.Cut(arg, "=")
+			if !ok {
+				return fmt.Errorf(text.Text.Errors.SetMissingValue, arg)
+			}
+			if !validKey.MatchString(key) {
+				return fmt.Errorf(text.Text.Errors.InvalidKey, key)
+			}
+			values = append(values, tomledit.KeyValue{Key: key, Value: value})
+		}
+
+		source := ""
+		if newFromFlag != "" {
+			source = config.BuildConfigName(namespaceFlag, newFromFlag)
+			if _, ok := cfg.Configs[source]; !ok {
+				return fmt.Errorf(text.Text.Errors.ConfigNotFound, newFromFlag)
+			}
+		}
+
+		err = config.UpdateConfigFile(func(doc *tomledit.Document) error {
+			if source == "" {
+				doc.AppendTable(target, values)
+				return nil
+			}
+			copyConfig(doc, cfg, source, target)
+			for _, kv := range values {
+				doc.SetValue(target, kv.Key, kv.Value)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf(text.Text.Messages.CreatedConfig, target)
+		return nil
+	},
+}
+
+var cpCmd = &cobra.Command{
+	Use:   text.Text.Commands.Cp.Use,
+	Short: text.Text.Commands.Cp.Short,
+	Long:  text.Text.Commands.Cp.Long,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngineWithNamespace(namespaceFlag)
+		if err != nil {
+			return err
+		}
+		cfg := engine.GetConfig()
+
+		source, err := engine.GetConfigFull(args[0])
+		if err != nil {
+			return err
+		}
+		target := config.BuildConfigName(namespaceFlag, args[1])
+		if err := checkNewName(cfg, target); err != nil {
+			return err
+		}
+
+		err = config.UpdateConfigFile(func(doc *tomledit.Document) error {
+			copyConfig(doc, cfg, source, target)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf(text.Text.Messages.CopiedConfig, source, target)
+		return nil
+	},
+}
+
+var mvCmd = &cobra.Command{
+	Use:   text.Text.Commands.Mv.Use,
+	Short: text.Text.Commands.Mv.Short,
+	Long:  text.Text.Commands.Mv.Long,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngineWithNamespace(namespaceFlag)
+		if err != nil {
+			return err
+		}
+
+		source, err := engine.GetConfigFull(args[0])
+		if err != nil {
+			return err
+		}
+		target := config.BuildConfigName(namespaceFlag, args[1])
+		if err := checkNewName(engine.GetConfig(), target); err != nil {
+			return err
+		}
+
+		err = config.UpdateConfigFile(func(doc *tomledit.Document) error {
+			if !doc.RenameTable(source, target) {
+				return fmt.Errorf(text.Text.Errors.ConfigTableNotEditable, source)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := engine.RenameConfig(source, target); err != nil {
+			return err
+		}
+		syncActiveEnv()
+
+		fmt.Printf(text.Text.Messages.RenamedConfig, source, target)
+		return nil
+	},
+}
+
+var rmCmd = &cobra.Command{
+	Use:   text.Text.Commands.Rm.Use,
+	Short: text.Text.Commands.Rm.Short,
+	Long:  text.Text.Commands.Rm.Long,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngineWithNamespace(namespaceFlag)
+		if err != nil {
+			return err
+		}
+
+		name, err := engine.GetConfigFull(args[0])
+		if err != nil {
+			return err
+		}
+
+		if !rmYesFlag {
+			ok, err := confirm(fmt.Sprintf(text.Text.Prompts.ConfirmRemoveConfig, name))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errors.New(text.Text.Errors.Aborted)
+			}
+		}
+
+		err = config.UpdateConfigFile(func(doc *tomledit.Document) error {
+			if !doc.DeleteTable(name) {
+				return fmt.Errorf(text.Text.Errors.ConfigTableNotEditable, name)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := engine.RemoveConfig(name); err != nil {
+			return err
+		}
+		syncActiveEnv()
+
+		fmt.Printf(text.Text.Messages.RemovedConfig, name)
+		return nil
+	},
+}
+
+// checkNewName reports whether name can be added to cfg without clashing with an
+// existing configuration or namespace
+func checkNewName(cfg *config.Config, name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf(text.Text.Errors.InvalidConfigName, name)
+	}
+	parts := strings.Split(name, ".")
+	for _, part := range parts {
+		if part == config.ReservedName {
+			return fmt.Errorf(text.Text.Errors.ReservedConfigName, name, config.ReservedName)
+		}
+	}
+	// A top-level [version] table would clash with the format version key
+	if parts[0] == config.VersionKey {
+		return fmt.Errorf(text.Text.Errors.ReservedConfigName, name, config.VersionKey)
+	}
+	if _, ok := cfg.Configs[name]; ok {
+		return fmt.Errorf(text.Text.Errors.ConfigExists, name)
+	}
+
+	// [db] cannot be both a configuration and the namespace of [db.local]
+	ns, _ := config.ParseConfigName(name)
+	if _, ok := cfg.Configs[ns]; ok && ns != "" {
+		return fmt.Errorf(text.Text.Errors.ConfigNameClash, name, ns)
+	}
+	for existing := range cfg.Configs {
+		if strings.HasPrefix(existing, name+".") {
+			return fmt.Errorf(text.Text.Errors.ConfigNameClash, name, name)
+		}
+	}
+	return nil
+}
+
+// copyConfig adds target as a copy of source. The table is copied as written,
+// comments included; a configuration that takes any value from the team
+// config is copied from its merged raw values, since its personal table only
+// holds the overrides
+func copyConfig(doc *tomledit.Document, cfg *config.Config, source, target string) {
+	if !fromTeamLayer(cfg, source) && doc.CopyTable(source, target) {
+		return
+	}
+
+	vars := cfg.Configs[source]
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sortVarsFirst(keys)
+
+	values := make([]tomledit.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, tomledit.KeyValue{Key: k, Value: vars[k]})
+	}
+	doc.AppendTable(target, values)
+	replaceCommands(doc, target, cfg.Commands[source])
+}
+
+// fromTeamLayer reports whether any value or command of name comes from the team config
+func fromTeamLayer(cfg *config.Config, name string) bool {
+	for _, layer := range cfg.Sources[name] {
+		if layer == config.LayerTeam {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceCommands writes commands as the [name._commands] table, removing the
+// table when there are none
+func replaceCommands(doc *tomledit.Document, name string, commands map[string]string) {
+	table := name + "." + config.CommandsTable
+	if len(commands) == 0 {
+		doc.DeleteTable(table)
+		return
+	}
+
+	values := make([]tomledit.KeyValue, 0, len(commands))
+	for _, k := range sortedKeys(commands) {
+		values = append(values, tomledit.KeyValue{Key: k, Value: commands[k]})
+	}
+	doc.ReplaceTable(table, values)
+}
+
+func init() {
+	newCmd.Flags().StringVar(&newFromFlag, "from", "", text.Text.Commands.Flags.NewFrom)
+	rmCmd.Flags().BoolVarP(&rmYesFlag, "yes", "y", false, text.Text.Commands.Flags.Yes)
+}
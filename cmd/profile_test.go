@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"envpick/internal/config"
+	"envpick/internal/tomledit"
+)
+
+func TestCheckNewName(t *testing.T) {
+	cfg := &config.Config{Configs: map[string]map[string]string{
+		"dev":      {},
+		"db.local": {},
+	}}
+
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{name: "work", valid: true},
+		{name: "db.prod", valid: true},
+		{name: "db.version", valid: true},
+		{name: "dev", valid: false},
+		{name: "db", valid: false},
+		{name: "dev.x", valid: false},
+		{name: "default", valid: false},
+		{name: "db.default", valid: false},
+		{name: "version", valid: false},
+		{name: "version.prod", valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkNewName(cfg, tt.name)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestCopyConfigWithTeamValues(t *testing.T) {
+	// The personal table of a team configuration only holds the overrides
+	cfg := &config.Config{
+		Configs: map[string]map[string]string{
+			"work": {"ANTHROPIC_BASE_URL": "https://api.company.com", "ANTHROPIC_MODEL": "mine"},
+		},
+		Sources: map[string]map[string]string{
+			"work": {"ANTHROPIC_BASE_URL": config.LayerTeam, "ANTHROPIC_MODEL": config.LayerPersonal, "_commands.deploy": config.LayerTeam},
+		},
+		Commands: map[string]map[string]string{"work": {"deploy": "make deploy"}},
+	}
+	doc := tomledit.Parse([]byte("[work]\nANTHROPIC_MODEL = \"mine\"\n"))
+
+	copyConfig(doc, cfg, "work", "copy")
+
+	assert.Equal(t, `[work]
+ANTHROPIC_MODEL = "mine"
+
+[copy]
+ANTHROPIC_BASE_URL = "https://api.company.com"
+ANTHROPIC_MODEL = "mine"
+
+[copy._commands]
+deploy = "make deploy"
+`, string(doc.Bytes()))
+}
+
+func TestCopyConfigPersonal(t *testing.T) {
+	// Personal configurations are copied as written, comments included
+	cfg := &config.Config{
+		Configs: map[string]map[string]string{"dev": {"A": "1"}},
+		Sources: map[string]map[string]string{"dev": {"A": config.LayerPersonal}},
+	}
+	doc := tomledit.Parse([]byte("# mine\n[dev]\nA = \"1\" # note\n"))
+
+	copyConfig(doc, cfg, "dev", "copy")
+
+	assert.Equal(t, "# mine\n[dev]\nA = \"1\" # note\n\n[copy]\nA = \"1\" # note\n", string(doc.Bytes()))
+}
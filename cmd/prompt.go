@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// stdin is shared by all prompts so that buffered input is not lost between them
+var stdin = bufio.NewReader(os.Stdin)
+
+// prompt asks a question and returns the answer without surrounding whitespace.
+// The question goes to stderr so that it never ends up in evaluated output.
+func prompt(question string) (string, error) {
+	fmt.Fprint(os.Stderr, question)
+
+	answer, err := stdin.ReadString('\n')
+	if err != nil && answer == "" {
+		return "", err
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+// confirm asks a yes/no question and reports whether the user answered yes.
+func confirm(question string) (bool, error) {
+	answer, err := prompt(question)
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
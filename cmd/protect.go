@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"envpick/internal/config"
+	"envpick/internal/text"
+)
+
+var (
+	protectedYesFlag bool
+	reasonFlag       string
+)
+
+// Actions recorded in the audit log
+const (
+	actionUse   = "use"
+	actionTmp   = "tmp" // ep tmp: envpick use --session, or envpick env select
+	actionRun   = "run"
+	actionShell = "shell"
+)
+
+// confirmProtected makes the user type the name of a protected configuration
+// (or pass --yes) before it is activated. A reason is asked for on 'use' if the
+// configuration requires one. It returns the audit log entry to record with
+// recordActivation once the activation succeeded, nil if there is none.
+func confirmProtected(cfg *config.Config, fullName, action string) (*config.AuditEntry, error) {
+	entry, err := cfg.GetEntry(fullName)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.Protected && !entry.RequireReason {
+		return nil, nil
+	}
+
+	if entry.Protected && !protectedYesFlag {
+		typed, err := prompt(fmt.Sprintf(text.Text.Prompts.TypeProtectedName, fullName))
+		if err != nil {
+			return nil, err
+		}
+		_, short := config.ParseConfigName(fullName)
+		if typed != fullName && typed != short {
+			return nil, fmt.Errorf(text.Text.Errors.ProtectedNotConfirmed, fullName)
+		}
+	}
+
+	reason := reasonFlag
+	if action == actionUse && entry.RequireReason && reason == "" {
+		if protectedYesFlag {
+			return nil, fmt.Errorf(text.Text.Errors.ReasonRequired, fullName)
+		}
+		if reason, err = prompt(fmt.Sprintf(text.Text.Prompts.EnterReason, fullName)); err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			return nil, fmt.Errorf(text.Text.Errors.ReasonRequired, fullName)
+		}
+	}
+
+	return &config.AuditEntry{
+		User:   currentUser(),
+		Action: action,
+		Config: fullName,
+		Reason: reason,
+	}, nil
+}
+
+// recordActivation appends the entry returned by confirmProtected to the audit
+// log, after hooks ran and the activation took effect. The activation cannot
+// be undone at that point, so a failure to write is only reported.
+func recordActivation(entry *config.AuditEntry) {
+	if entry == nil {
+		return
+	}
+	entry.Time = time.Now().UTC()
+	if err := config.AppendAudit(*entry); err != nil {
+		fmt.Fprintf(os.Stderr, text.Text.Messages.AuditFailed, err)
+	}
+}
+
+// currentUser returns the login name for the audit log
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
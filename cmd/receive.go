@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/bundle"
+	"envpick/internal/config"
+	"envpick/internal/core"
+	"envpick/internal/resolve"
+	"envpick/internal/text"
+	"envpick/internal/tomledit"
+)
+
+var (
+	receiveAsFlag   string
+	receiveFromFlag string
+	receiveYesFlag  bool
+)
+
+var receiveCmd = &cobra.Command{
+	Use:   text.Text.Commands.Receive.Use,
+	Short: text.Text.Commands.Receive.Short,
+	Long:  text.Text.Commands.Receive.Long,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf(text.Text.Errors.BundleRead, err)
+		}
+
+		identity, err := config.LoadOrCreateIdentity()
+		if err != nil {
+			return err
+		}
+
+		b, err := bundle.Open(data, identity)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf(text.Text.Messages.ReceivedBundle, b.Profile, b.Fingerprint(), b.Created.Local().Format("2006-01-02 15:04"))
+		if err := checkSigner(b); err != nil {
+			return err
+		}
+
+		target := b.Profile
+		if receiveAsFlag != "" {
+			target = config.BuildConfigName(namespaceFlag, receiveAsFlag)
+		}
+
+		// A missing config file simply means this is the first profile
+		cfg, err := config.LoadConfigOrEmpty()
+		if err != nil {
+			return err
+		}
+		existing := map[string]string{}
+		var existingCommands map[string]string
+		if vars, ok := cfg.Configs[target]; ok {
+			existing = vars
+			existingCommands = cfg.Commands[target]
+			fmt.Fprintf(os.Stderr, text.Text.Messages.ReceiveConflict, target)
+		}
+
+		printKeyChanges(existing, b.Values)
+		printKeyChanges(commandKeys(existingCommands), commandKeys(b.Commands))
+		printExecutable(b.Values, b.Commands)
+
+		if !receiveYesFlag {
+			ok, err := confirm(fmt.Sprintf(text.Text.Prompts.ConfirmWriteProfile, target))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errors.New(text.Text.Errors.Aborted)
+			}
+		}
+
+		keys := make([]string, 0, len(b.Values))
+		for k := range b.Values {
+			keys = append(keys, k)
+		}
+		sortVarsFirst(keys)
+
+		values := make([]tomledit.KeyValue, 0, len(keys))
+		for _, k := range keys {
+			values = append(values, tomledit.KeyValue{Key: k, Value: b.Values[k]})
+		}
+
+		err = config.UpdateConfigFile(func(doc *tomledit.Document) error {
+			if len(existing) > 0 && !doc.HasTable(target) {
+				return fmt.Errorf(text.Text.Errors.ConfigTableNotEditable, target)
+			}
+			doc.ReplaceTable(target, values)
+			replaceCommands(doc, target, b.Commands)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf(text.Text.Messages.WroteProfile, target)
+		return nil
+	},
+}
+
+// printKeyChanges lists added, removed and changed keys without revealing values
+func printKeyChanges(before, after map[string]string) {
+	for _, c := range core.DiffVars(before, after) {
+		switch c.Kind {
+		case core.ChangeAdded:
+			fmt.Printf(text.Text.Formats.KeyAdded, c.Key)
+		case core.ChangeRemoved:
+			fmt.Printf(text.Text.Formats.KeyRemoved, c.Key)
+		case core.ChangeChanged:
+			fmt.Printf(text.Text.Formats.KeyChanged, c.Key)
+		}
+	}
+}
+
+// checkSigner accepts the signer of b if it matches --from or is listed in the
+// trusted signers file. Otherwise its fingerprint must be confirmed, which
+// --yes does not do: the signature alone only proves who holds the embedded key.
+func checkSigner(b *bundle.Bundle) error {
+	fingerprint := b.Fingerprint()
+	if receiveFromFlag != "" {
+		if receiveFromFlag != fingerprint {
+			return fmt.Errorf(text.Text.Errors.BundleUnexpectedSigner, fingerprint, receiveFromFlag)
+		}
+		return nil
+	}
+
+	trusted, err := config.IsTrustedSigner(fingerprint)
+	if err != nil || trusted {
+		return err
+	}
+
+	path, err := config.GetTrustedSignersPath()
+	if err != nil {
+		return err
+	}
+	if receiveYesFlag {
+		return fmt.Errorf(text.Text.Errors.BundleUntrustedSigner, fingerprint, fingerprint, path)
+	}
+
+	fmt.Fprintf(os.Stderr, text.Text.Messages.UntrustedSigner, fingerprint)
+	ok, err := confirm(fmt.Sprintf(text.Text.Prompts.ConfirmTrustSigner, fingerprint))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New(text.Text.Errors.Aborted)
+	}
+	if err := config.TrustSigner(fingerprint, b.Profile); err != nil {
+		return err
+	}
+	fmt.Printf(text.Text.Messages.TrustedSigner, fingerprint, path)
+	return nil
+}
+
+// printExecutable shows, verbatim, the hooks and commands of a configuration
+// and the values it takes from providers, since they run once it is used
+func printExecutable(values, commands map[string]string) {
+	executable := commandKeys(commands)
+	for k, v := range values {
+		if config.IsCommandKey(k) || resolve.IsRef(v) {
+			executable[k] = v
+		}
+	}
+	if len(executable) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(executable))
+	for k := range executable {
+		keys = append(keys, k)
+	}
+	sortVarsFirst(keys)
+	fmt.Print(text.Text.Messages.BundleExecutable)
+	for _, k := range keys {
+		fmt.Printf(text.Text.Formats.KeyExecutable, k, executable[k])
+	}
+}
+
+// commandKeys returns commands keyed for display as _commands.<name>
+func commandKeys(commands map[string]string) map[string]string {
+	keyed := make(map[string]string, len(commands))
+	for name, command := range commands {
+		keyed[config.CommandsTable+"."+name] = command
+	}
+	return keyed
+}
+
+// sortVarsFirst sorts keys alphabetically with metadata keys (_ prefix) last
+func sortVarsFirst(keys []string) {
+	sort.Slice(keys, func(i, j int) bool {
+		mi, mj := strings.HasPrefix(keys[i], "_"), strings.HasPrefix(keys[j], "_")
+		if mi != mj {
+			return !mi
+		}
+		return keys[i] < keys[j]
+	})
+}
+
+func init() {
+	receiveCmd.Flags().StringVar(&receiveAsFlag, "as", "", text.Text.Commands.Flags.ReceiveAs)
+	receiveCmd.Flags().StringVar(&receiveFromFlag, "from", "", text.Text.Commands.Flags.ReceiveFrom)
+	receiveCmd.Flags().BoolVarP(&receiveYesFlag, "yes", "y", false, text.Text.Commands.Flags.Yes)
+}
@@ -32,4 +32,30 @@ func init() {
 	rootCmd.AddCommand(envCmd)
 	rootCmd.AddCommand(editCmd)
 	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(shareCmd)
+	rootCmd.AddCommand(receiveCmd)
+	rootCmd.AddCommand(identityCmd)
+	rootCmd.AddCommand(layersCmd)
+	rootCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(whichCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(getCmd)
+	rootCmd.AddCommand(setCmd)
+	rootCmd.AddCommand(unsetCmd)
+	rootCmd.AddCommand(newCmd)
+	rootCmd.AddCommand(cpCmd)
+	rootCmd.AddCommand(mvCmd)
+	rootCmd.AddCommand(rmCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(captureCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(syncEnvCmd)
+	rootCmd.AddCommand(shellCmd)
+	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(offCmd)
+	rootCmd.AddCommand(migrateCmd)
 }
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/core"
+	"envpick/internal/selector"
+	"envpick/internal/text"
+)
+
+var runCmd = &cobra.Command{
+	Use:               text.Text.Commands.Run.Use,
+	Short:             text.Text.Commands.Run.Short,
+	Long:              text.Text.Commands.Run.Long,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeRunArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngineWithNamespace(namespaceFlag)
+		if err != nil {
+			return err
+		}
+
+		fullName, err := engine.GetConfigFull(args[0])
+		if err != nil {
+			return err
+		}
+		cfg := engine.GetConfig()
+		entry, err := cfg.GetEntry(fullName)
+		if err != nil {
+			return err
+		}
+		if len(entry.Commands) == 0 {
+			return fmt.Errorf(text.Text.Errors.NoCommands, fullName)
+		}
+		activation, err := confirmProtected(cfg, fullName, actionRun)
+		if err != nil {
+			return err
+		}
+
+		var name string
+		if len(args) > 1 {
+			name = args[1]
+		} else {
+			var options []selector.Option
+			for _, n := range sortedKeys(entry.Commands) {
+				options = append(options, selector.Option{Name: n})
+			}
+			name, err = selector.Select(options, text.Text.Prompts.SelectCommand)
+			if err != nil {
+				return err
+			}
+		}
+
+		command, ok := entry.Commands[name]
+		if !ok {
+			return fmt.Errorf(text.Text.Errors.CommandNotFound, name, fullName)
+		}
+
+		resolved, err := cfg.GetResolvedVars(fullName)
+		if err != nil {
+			return err
+		}
+
+		// Extra arguments are available to the command as "$@"; a "--" in
+		// front of them, which older versions needed, is dropped
+		var extra []string
+		if len(args) > 2 {
+			extra = args[2:]
+			if extra[0] == "--" {
+				extra = extra[1:]
+			}
+		}
+		child := exec.Command("sh", append([]string{"-c", command, name}, extra...)...)
+		child.Env = core.ApplyEnviron(os.Environ(), resolved[fullName])
+		child.Stdin = os.Stdin
+		child.Stdout = os.Stdout
+		child.Stderr = os.Stderr
+
+		// Let the command handle Ctrl-C, as in the shell command
+		interrupts := make(chan os.Signal, 1)
+		signal.Notify(interrupts, os.Interrupt)
+		defer signal.Stop(interrupts)
+
+		if err := child.Start(); err != nil {
+			return fmt.Errorf(text.Text.Errors.CommandStart, name, err)
+		}
+		recordActivation(activation)
+
+		err = child.Wait()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// Pass the command's exit status on to scripts
+			os.Exit(exitStatus(exitErr))
+		}
+		if err != nil {
+			return fmt.Errorf(text.Text.Errors.CommandStart, name, err)
+		}
+		return nil
+	},
+}
+
+// completeRunArgs completes configuration names, then the command names of
+// the chosen configuration
+func completeRunArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	engine, err := core.NewEngineWithNamespace(namespaceFlag)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	switch len(args) {
+	case 0:
+		var names []string
+		for _, info := range engine.ListConfigs(false) {
+			names = append(names, info.Config)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		fullName, err := engine.GetConfigFull(args[0])
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		entry, err := engine.GetConfig().GetEntry(fullName)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return sortedKeys(entry.Commands), cobra.ShellCompDirectiveNoFileComp
+	default:
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+}
+
+func init() {
+	// Flags after the configuration name belong to the command
+	runCmd.Flags().SetInterspersed(false)
+	runCmd.Flags().BoolVarP(&protectedYesFlag, "yes", "y", false, text.Text.Commands.Flags.ConfirmProtected)
+}
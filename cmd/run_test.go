@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPassesArguments(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	out := filepath.Join(t.TempDir(), "args")
+	t.Setenv("RUN_TEST_OUT", out)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".envpick"), 0755))
+	config := "[db.staging]\nDATABASE_URL = \"postgres://staging\"\n\n" +
+		"[db.staging._commands]\nconsole = 'printf \"%s\\n\" \"$DATABASE_URL\" \"$@\" > \"$RUN_TEST_OUT\"'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".envpick", "config.toml"), []byte(config), 0644))
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{name: "flags after the name", args: []string{"run", "-n", "db", "staging", "console", "--verbose", "-n", "x"}, expected: "postgres://staging\n--verbose\n-n\nx\n"},
+		{name: "separator", args: []string{"run", "db.staging", "console", "--", "--dry-run"}, expected: "postgres://staging\n--dry-run\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespaceFlag = ""
+			rootCmd.SetArgs(tt.args)
+			require.NoError(t, rootCmd.Execute())
+
+			data, err := os.ReadFile(out)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(data))
+		})
+	}
+}
+
+func TestRunConfirmsProtected(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	out := filepath.Join(t.TempDir(), "ran")
+	t.Setenv("RUN_TEST_OUT", out)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".envpick"), 0755))
+	config := "[prod]\n_protected = \"true\"\n\n[prod._commands]\ndeploy = 'touch \"$RUN_TEST_OUT\"'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".envpick", "config.toml"), []byte(config), 0644))
+	defer func() {
+		protectedYesFlag = false
+		stdin = bufio.NewReader(os.Stdin)
+	}()
+
+	namespaceFlag = ""
+	stdin = bufio.NewReader(strings.NewReader("staging\n"))
+	rootCmd.SetArgs([]string{"run", "prod", "deploy"})
+	require.Error(t, rootCmd.Execute(), "a wrong name should not confirm")
+	assert.NoFileExists(t, out)
+
+	rootCmd.SetArgs([]string{"run", "--yes", "prod", "deploy"})
+	require.NoError(t, rootCmd.Execute())
+	assert.FileExists(t, out)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"github.com/spf13/cobra"
+
+	"envpick/internal/bundle"
+	"envpick/internal/config"
+	"envpick/internal/core"
+	"envpick/internal/text"
+)
+
+var (
+	shareToFlag     string
+	shareOutputFlag string
+)
+
+var shareCmd = &cobra.Command{
+	Use:   text.Text.Commands.Share.Use,
+	Short: text.Text.Commands.Share.Short,
+	Long:  text.Text.Commands.Share.Long,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngineWithNamespace(namespaceFlag)
+		if err != nil {
+			return err
+		}
+
+		recipient, err := age.ParseX25519Recipient(strings.TrimSpace(shareToFlag))
+		if err != nil {
+			return fmt.Errorf(text.Text.Errors.ShareInvalidRecipient, err)
+		}
+
+		fullName := config.BuildConfigName(engine.GetNamespace(), args[0])
+		cfg := engine.GetConfig()
+		if _, ok := cfg.Configs[fullName]; !ok {
+			return fmt.Errorf(text.Text.Errors.ConfigNotFound, args[0])
+		}
+
+		// Share resolved values: provider references only work on the sender's machine
+		resolved, err := cfg.GetResolvedVars(fullName)
+		if err != nil {
+			return err
+		}
+		values := resolved[fullName]
+		for k, v := range cfg.Configs[fullName] {
+			if strings.HasPrefix(k, "_") {
+				values[k] = v
+			}
+		}
+
+		signingKey, err := config.LoadOrCreateSigningKey()
+		if err != nil {
+			return err
+		}
+
+		b := &bundle.Bundle{
+			Profile:  fullName,
+			Created:  time.Now().UTC().Truncate(time.Second),
+			Values:   values,
+			Commands: cfg.Commands[fullName],
+		}
+		data, err := bundle.Seal(b, signingKey, recipient)
+		if err != nil {
+			return err
+		}
+
+		output := shareOutputFlag
+		if output == "" {
+			output = fullName + text.Text.Formats.BundleExtension
+		}
+		if err := os.WriteFile(output, data, 0600); err != nil {
+			return fmt.Errorf(text.Text.Errors.BundleWrite, err)
+		}
+
+		fmt.Printf(text.Text.Messages.SharedBundle, fullName, output, b.Fingerprint())
+		return nil
+	},
+}
+
+func init() {
+	shareCmd.Flags().StringVar(&shareToFlag, "to", "", text.Text.Commands.Flags.ShareTo)
+	shareCmd.Flags().StringVarP(&shareOutputFlag, "output", "o", "", text.Text.Commands.Flags.ShareOutput)
+	_ = shareCmd.MarkFlagRequired("to")
+}
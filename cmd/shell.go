@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/core"
+	"envpick/internal/text"
+)
+
+// shellEnvVar names the configuration of an envpick subshell, for prompts
+const shellEnvVar = "ENVPICK_SHELL"
+
+var shellCmd = &cobra.Command{
+	Use:   text.Text.Commands.Shell.Use,
+	Short: text.Text.Commands.Shell.Short,
+	Long:  text.Text.Commands.Shell.Long,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngineWithNamespace(namespaceFlag)
+		if err != nil {
+			return err
+		}
+
+		fullName, err := engine.GetConfigFull(args[0])
+		if err != nil {
+			return err
+		}
+		activation, err := confirmProtected(engine.GetConfig(), fullName, actionShell)
+		if err != nil {
+			return err
+		}
+		resolved, err := engine.GetConfig().GetResolvedVars(fullName)
+		if err != nil {
+			return err
+		}
+
+		if outer := os.Getenv(shellEnvVar); outer != "" {
+			fmt.Fprintf(os.Stderr, text.Text.Messages.NestedShell, outer)
+		}
+
+		shell := os.Getenv("SHELL")
+		if shell == "" {
+			shell = "/bin/sh"
+		}
+
+		vars := resolved[fullName]
+		vars[shellEnvVar] = fullName
+
+		child := exec.Command(shell)
+		// The lease and follow state of the parent shell doesn't apply to the subshell
+		environ := core.UnsetEnviron(os.Environ(), leaseExpiresVar, leaseKeysVar, selectionsVar)
+		child.Env = core.ApplyEnviron(environ, vars)
+		child.Stdin = os.Stdin
+		child.Stdout = os.Stdout
+		child.Stderr = os.Stderr
+
+		// Ctrl-C belongs to the subshell; envpick must outlive it. Catching
+		// (rather than ignoring) the signal keeps the default for the child.
+		interrupts := make(chan os.Signal, 1)
+		signal.Notify(interrupts, os.Interrupt)
+		defer signal.Stop(interrupts)
+
+		fmt.Fprintf(os.Stderr, text.Text.Messages.EnteringShell, fullName)
+		if err := child.Start(); err != nil {
+			return fmt.Errorf(text.Text.Errors.ShellStart, shell, err)
+		}
+		recordActivation(activation)
+
+		err = child.Wait()
+		fmt.Fprintf(os.Stderr, text.Text.Messages.LeftShell, fullName)
+
+		// The exit status of the last command in the subshell is not an envpick
+		// error, but it is passed on like 'envpick run' does
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitStatus(exitErr))
+		}
+		if err != nil {
+			return fmt.Errorf(text.Text.Errors.ShellStart, shell, err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	shellCmd.Flags().BoolVarP(&protectedYesFlag, "yes", "y", false, text.Text.Commands.Flags.ConfirmProtected)
+}
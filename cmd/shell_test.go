@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestShellExitStatus runs 'envpick shell' in a child test process, as the
+// exit status of the subshell is passed on with os.Exit.
+func TestShellExitStatus(t *testing.T) {
+	if os.Getenv("ENVPICK_TEST_SHELL") == "1" {
+		namespaceFlag = ""
+		rootCmd.SetArgs([]string{"shell", "dev"})
+		if err := rootCmd.Execute(); err != nil {
+			os.Exit(100)
+		}
+		return
+	}
+
+	home := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".envpick"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".envpick", "config.toml"), []byte("[dev]\nA = \"1\"\n"), 0644))
+
+	child := exec.Command(os.Args[0], "-test.run=^TestShellExitStatus$")
+	child.Env = append(os.Environ(), "ENVPICK_TEST_SHELL=1", "HOME="+home, "SHELL=/bin/sh")
+	child.Stdin = strings.NewReader("test \"$A\" = 1 && exit 3\n")
+	err := child.Run()
+
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr), "envpick shell should fail with the subshell's status: %v", err)
+	assert.Equal(t, 3, exitErr.ExitCode())
+}
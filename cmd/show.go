@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/config"
+	"envpick/internal/core"
+	"envpick/internal/resolve"
+	"envpick/internal/text"
+)
+
+var (
+	showRevealFlag bool
+	showYesFlag    bool
+)
+
+var showCmd = &cobra.Command{
+	Use:   text.Text.Commands.Show.Use,
+	Short: text.Text.Commands.Show.Short,
+	Long:  text.Text.Commands.Show.Long,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngineWithNamespace(namespaceFlag)
+		if err != nil {
+			return err
+		}
+
+		var name string
+		if len(args) > 0 {
+			name = args[0]
+		}
+		fullName, err := engine.GetConfigFull(name)
+		if err != nil {
+			return err
+		}
+
+		if showRevealFlag && !showYesFlag {
+			ok, err := confirm(fmt.Sprintf(text.Text.Prompts.ConfirmReveal, fullName))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errors.New(text.Text.Errors.Aborted)
+			}
+		}
+
+		cfg := engine.GetConfig()
+		entry, err := cfg.GetEntry(fullName)
+		if err != nil {
+			return err
+		}
+		resolved, err := cfg.GetResolvedVars(fullName)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf(text.Text.Formats.ShowHeader, fullName)
+
+		fingerprintKey, err := config.LoadOrCreateFingerprintKey()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprint(w, text.Text.Formats.ShowVariables)
+		for _, k := range sortedKeys(entry.Vars) {
+			value := formatValue(entry, k, resolved[fullName][k], showRevealFlag, fingerprintKey)
+			if scheme, _, ok := resolve.ParseRef(entry.Vars[k]); ok {
+				value += fmt.Sprintf(text.Text.Formats.ShowVia, scheme)
+			}
+			fmt.Fprintf(w, text.Text.Formats.ShowRow, k, value)
+		}
+
+		if len(entry.Commands) > 0 {
+			fmt.Fprint(w, text.Text.Formats.ShowCommands)
+			for _, k := range sortedKeys(entry.Commands) {
+				fmt.Fprintf(w, text.Text.Formats.ShowRow, k, entry.Commands[k])
+			}
+		}
+
+		if len(entry.Metadata) > 0 {
+			fmt.Fprint(w, text.Text.Formats.ShowMetadata)
+			for _, k := range sortedKeys(entry.Metadata) {
+				fmt.Fprintf(w, text.Text.Formats.ShowRow, k, entry.Metadata[k])
+			}
+		}
+		return w.Flush()
+	},
+}
+
+// formatValue returns value for display, masked by its fingerprint under
+// fingerprintKey if key is secret
+func formatValue(entry *config.ConfigEntry, key, value string, reveal bool, fingerprintKey []byte) string {
+	switch {
+	case value == "":
+		return text.Text.Formats.EmptyValue
+	case entry.IsSecret(key) && !reveal:
+		return maskValue(fingerprintKey, value)
+	default:
+		return value
+	}
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func init() {
+	showCmd.Flags().BoolVar(&showRevealFlag, "reveal", false, text.Text.Commands.Flags.Reveal)
+	showCmd.Flags().BoolVarP(&showYesFlag, "yes", "y", false, text.Text.Commands.Flags.Yes)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/core"
+	"envpick/internal/text"
+)
+
+var statusFormatFlag string
+
+var statusCmd = &cobra.Command{
+	Use:   text.Text.Commands.Status.Use,
+	Short: text.Text.Commands.Status.Short,
+	Long:  text.Text.Commands.Status.Long,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngine()
+		if err != nil {
+			return err
+		}
+
+		statuses, err := engine.GetStatus(core.ParseEnviron(os.Environ()))
+		if err != nil {
+			return err
+		}
+
+		switch statusFormatFlag {
+		case "json":
+			if statuses == nil {
+				statuses = []core.NamespaceStatus{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(statuses)
+		case "table":
+			if len(statuses) == 0 {
+				fmt.Print(text.Text.Messages.NoActiveConfigs)
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprint(w, text.Text.Formats.StatusTableHeader)
+			for _, s := range statuses {
+				state := s.Status
+				if s.Status != core.StatusMissing {
+					state = fmt.Sprintf(text.Text.Formats.StatusCount, s.Status, s.Matched, s.Total)
+				}
+				lease := ""
+				if s.LeaseExpires != nil {
+					lease = text.Text.Formats.LeaseExpiredStatus
+					if remaining := time.Until(*s.LeaseExpires).Round(time.Second); remaining > 0 {
+						lease = remaining.String()
+					}
+				}
+				name := s.Config
+				if s.Session {
+					name += text.Text.Formats.SessionSuffix
+				}
+				fmt.Fprintf(w, text.Text.Formats.StatusTableRow, displayNamespace(s.Namespace), name, state, lease, strings.Join(s.Differing, ", "))
+			}
+			return w.Flush()
+		default:
+			return fmt.Errorf(text.Text.Errors.UnknownFormat, statusFormatFlag)
+		}
+	},
+}
+
+func init() {
+	statusCmd.Flags().StringVarP(&statusFormatFlag, "format", "f", "table", text.Text.Commands.Flags.StatusFormat)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/core"
+	"envpick/internal/text"
+)
+
+var syncEnvCmd = &cobra.Command{
+	Use:   text.Text.Commands.SyncEnv.Use,
+	Short: text.Text.Commands.SyncEnv.Short,
+	Long:  text.Text.Commands.SyncEnv.Long,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngine()
+		if err != nil {
+			return err
+		}
+
+		written, err := engine.SyncActiveEnv()
+		if err != nil {
+			return err
+		}
+		for _, path := range written {
+			fmt.Printf(text.Text.Messages.SyncedActiveEnv, path)
+		}
+		return nil
+	},
+}
+
+// syncActiveEnv refreshes the active env files after the persisted selection
+// changed. The selection itself has already been saved, so failures are only
+// reported.
+func syncActiveEnv() {
+	engine, err := core.NewEngine()
+	if err == nil {
+		_, err = engine.SyncActiveEnv()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, text.Text.Messages.ActiveEnvSyncFailed, err)
+	}
+}
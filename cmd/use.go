@@ -3,37 +3,95 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"envpick/internal/config"
 	"envpick/internal/core"
 	"envpick/internal/selector"
 	"envpick/internal/text"
 )
 
+var (
+	noHooksFlag    bool
+	useForFlag     time.Duration
+	useSessionFlag bool
+)
+
 var useCmd = &cobra.Command{
 	Use:   text.Text.Commands.Use.Use,
 	Short: text.Text.Commands.Use.Short,
 	Long:  text.Text.Commands.Use.Long,
+	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		leased := cmd.Flags().Changed("for")
+		if useSessionFlag && leased {
+			return errors.New(text.Text.Errors.SessionWithLease)
+		}
+
 		engine, err := core.NewEngineWithNamespace(namespaceFlag)
 		if err != nil {
 			return err
 		}
 
-		options := engine.GetOptions()
-		if len(options) == 0 {
-			return errors.New(text.Text.Errors.NoConfigurationsUse)
+		var selected string
+		if len(args) > 0 && args[0] == "-" {
+			// Toggle back to the previous selection
+			if selected, err = engine.PreviousConfig(); err != nil {
+				return err
+			}
+		} else if len(args) > 0 {
+			if _, err := engine.GetConfigFull(args[0]); err != nil {
+				return err
+			}
+			selected = args[0]
+		} else {
+			options := engine.GetOptions()
+			if len(options) == 0 {
+				return errors.New(text.Text.Errors.NoConfigurationsUse)
+			}
+
+			selected, err = selector.Select(options, text.Text.Prompts.SelectConfiguration)
+			if err != nil {
+				return err
+			}
 		}
 
-		selected, err := selector.Select(options, text.Text.Prompts.SelectConfiguration)
+		next := config.BuildConfigName(engine.GetNamespace(), selected)
+		action := actionUse
+		if useSessionFlag {
+			action = actionTmp
+		}
+		activation, err := confirmProtected(engine.GetConfig(), next, action)
 		if err != nil {
 			return err
 		}
 
-		if err := engine.SetCurrentConfig(selected); err != nil {
+		if !noHooksFlag {
+			if err := engine.RunSwitchHooks(engine.GetCurrentConfigFull(), next, os.Stderr); err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case useSessionFlag:
+			err = engine.SetSessionConfig(selected)
+		case leased:
+			err = engine.SetCurrentConfigFor(selected, useForFlag)
+		default:
+			err = engine.SetCurrentConfig(selected)
+		}
+		if err != nil {
 			return err
 		}
+		recordActivation(activation)
+		if useSessionFlag {
+			fmt.Printf(text.Text.Messages.SwitchedToConfigSession, next)
+			return nil
+		}
+		syncActiveEnv()
 
 		// Show namespace in output if non-default
 		if engine.GetNamespace() != "" {
@@ -41,6 +99,17 @@ var useCmd = &cobra.Command{
 		} else {
 			fmt.Printf(text.Text.Messages.SwitchedToConfig, selected)
 		}
+		if lease, ok := engine.GetLease(engine.GetNamespace()); ok {
+			fmt.Printf(text.Text.Messages.LeaseUntil, useForFlag, lease.Expires.Local().Format("15:04"))
+		}
 		return nil
 	},
 }
+
+func init() {
+	useCmd.Flags().BoolVar(&noHooksFlag, "no-hooks", false, text.Text.Commands.Flags.NoHooks)
+	useCmd.Flags().BoolVarP(&protectedYesFlag, "yes", "y", false, text.Text.Commands.Flags.ConfirmProtected)
+	useCmd.Flags().StringVar(&reasonFlag, "reason", "", text.Text.Commands.Flags.Reason)
+	useCmd.Flags().DurationVar(&useForFlag, "for", 0, text.Text.Commands.Flags.UseFor)
+	useCmd.Flags().BoolVar(&useSessionFlag, "session", false, text.Text.Commands.Flags.UseSession)
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/config"
+	"envpick/internal/core"
+	"envpick/internal/text"
+	"envpick/internal/tomledit"
+)
+
+var (
+	valueAllFlag       bool
+	valueFromStdinFlag bool
+	valueRawFlag       bool
+)
+
+// validKey matches variable and metadata names that can be written to config.toml
+var validKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var getCmd = &cobra.Command{
+	Use:   text.Text.Commands.Get.Use,
+	Short: text.Text.Commands.Get.Short,
+	Long:  text.Text.Commands.Get.Long,
+	Args:  valueArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngineWithNamespace(namespaceFlag)
+		if err != nil {
+			return err
+		}
+
+		names, args, err := valueTargets(engine, args)
+		if err != nil {
+			return err
+		}
+		key := args[0]
+
+		cfg := engine.GetConfig()
+		values := make(map[string]string)
+		if valueRawFlag || strings.HasPrefix(key, "_") {
+			for _, name := range names {
+				values[name] = cfg.Configs[name][key]
+			}
+		} else {
+			resolved, err := cfg.GetResolvedVars(names...)
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				values[name] = resolved[name][key]
+			}
+		}
+
+		for _, name := range names {
+			_, found := cfg.Configs[name][key]
+			switch {
+			case valueAllFlag && found:
+				_, short := config.ParseConfigName(name)
+				fmt.Printf(text.Text.Formats.GetAllRow, short, values[name])
+			case !valueAllFlag && !found:
+				return fmt.Errorf(text.Text.Errors.KeyNotFound, key, name)
+			case !valueAllFlag:
+				fmt.Println(values[name])
+			}
+		}
+		return nil
+	},
+}
+
+var setCmd = &cobra.Command{
+	Use:   text.Text.Commands.Set.Use,
+	Short: text.Text.Commands.Set.Short,
+	Long:  text.Text.Commands.Set.Long,
+	Args:  valueArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngineWithNamespace(namespaceFlag)
+		if err != nil {
+			return err
+		}
+
+		names, args, err := valueTargets(engine, args)
+		if err != nil {
+			return err
+		}
+
+		key, value, hasValue := strings.Cut(args[0], "=")
+		if valueFromStdinFlag {
+			if hasValue {
+				return errors.New(text.Text.Errors.ValueAndStdin)
+			}
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf(text.Text.Errors.StdinRead, err)
+			}
+			value, hasValue = strings.TrimRight(string(data), "\r\n"), true
+		}
+		if !hasValue {
+			return fmt.Errorf(text.Text.Errors.SetMissingValue, args[0])
+		}
+		if !validKey.MatchString(key) {
+			return fmt.Errorf(text.Text.Errors.InvalidKey, key)
+		}
+
+		var updated, skipped []string
+		err = config.UpdateConfigFile(func(doc *tomledit.Document) error {
+			for _, name := range names {
+				// With --all, configurations that only exist in the team
+				// config have no table of their own to write to
+				if valueAllFlag && !doc.HasTable(name) {
+					skipped = append(skipped, name)
+					continue
+				}
+				if !doc.SetValue(name, key, value) {
+					return fmt.Errorf(text.Text.Errors.ConfigTableNotEditable, name)
+				}
+				updated = append(updated, name)
+			}
+			if len(updated) == 0 {
+				return errors.New(text.Text.Errors.NoEditableConfigs)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, name := range skipped {
+			fmt.Fprintf(os.Stderr, text.Text.Messages.SetSkippedTeam, name)
+		}
+		for _, name := range updated {
+			fmt.Printf(text.Text.Messages.SetValue, key, name)
+		}
+		return nil
+	},
+}
+
+var unsetCmd = &cobra.Command{
+	Use:   text.Text.Commands.Unset.Use,
+	Short: text.Text.Commands.Unset.Short,
+	Long:  text.Text.Commands.Unset.Long,
+	Args:  valueArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngineWithNamespace(namespaceFlag)
+		if err != nil {
+			return err
+		}
+
+		names, args, err := valueTargets(engine, args)
+		if err != nil {
+			return err
+		}
+		key := args[0]
+
+		var removed []string
+		err = config.UpdateConfigFile(func(doc *tomledit.Document) error {
+			for _, name := range names {
+				if doc.DeleteValue(name, key) {
+					removed = append(removed, name)
+				} else if !valueAllFlag {
+					return fmt.Errorf(text.Text.Errors.KeyNotFound, key, name)
+				}
+			}
+			if len(removed) == 0 {
+				return fmt.Errorf(text.Text.Errors.KeyNotFoundAll, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, name := range removed {
+			fmt.Printf(text.Text.Messages.UnsetValue, key, name)
+		}
+		return nil
+	},
+}
+
+// valueArgs accepts n arguments after the configuration name, which --all omits
+func valueArgs(n int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if valueAllFlag {
+			return cobra.ExactArgs(n)(cmd, args)
+		}
+		return cobra.ExactArgs(n+1)(cmd, args)
+	}
+}
+
+// valueTargets returns the full names of the configurations a value command
+// applies to (every configuration of the namespace with --all), and the
+// remaining arguments
+func valueTargets(engine *core.Engine, args []string) ([]string, []string, error) {
+	if valueAllFlag {
+		var names []string
+		for _, info := range engine.ListConfigs(false) {
+			names = append(names, info.Name)
+		}
+		if len(names) == 0 {
+			return nil, nil, errors.New(text.Text.Errors.NoConfigurations)
+		}
+		return names, args, nil
+	}
+
+	fullName, err := engine.GetConfigFull(args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return []string{fullName}, args[1:], nil
+}
+
+func init() {
+	for _, c := range []*cobra.Command{getCmd, setCmd, unsetCmd} {
+		c.Flags().BoolVarP(&valueAllFlag, "all", "a", false, text.Text.Commands.Flags.ValueAll)
+	}
+	setCmd.Flags().BoolVar(&valueFromStdinFlag, "from-stdin", false, text.Text.Commands.Flags.FromStdin)
+	getCmd.Flags().BoolVar(&valueRawFlag, "raw", false, text.Text.Commands.Flags.Raw)
+}
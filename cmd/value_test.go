@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"envpick/internal/config"
+)
+
+// setupTeamLayer writes personal config and a signed, trusted team config
+// into a temporary HOME
+func setupTeamLayer(t *testing.T, personal, team string) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configDir := filepath.Join(home, ".envpick")
+	require.NoError(t, os.MkdirAll(configDir, 0755))
+	configPath := filepath.Join(configDir, "config.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte(personal), 0644))
+
+	teamPath := filepath.Join(t.TempDir(), "team.toml")
+	require.NoError(t, os.WriteFile(teamPath, []byte(team), 0644))
+	t.Setenv("ENVPICK_TEAM_CONFIG", teamPath)
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "team_keys"), []byte(config.EncodePublicKey(public)+"\n"), 0644))
+	_, err = config.SignTeamConfig(teamPath, private)
+	require.NoError(t, err)
+
+	return configPath
+}
+
+func TestSetAllSkipsTeamOnlyConfigs(t *testing.T) {
+	configPath := setupTeamLayer(t,
+		"[api.dev]\nAPI_TIMEOUT_MS = \"1000\"\n",
+		"[api.shared]\nAPI_TIMEOUT_MS = \"5000\"\n_overridable = \"*\"\n",
+	)
+	defer func() { valueAllFlag = false }()
+
+	namespaceFlag = ""
+	rootCmd.SetArgs([]string{"set", "--all", "-n", "api", "API_TIMEOUT_MS=600000"})
+	require.NoError(t, rootCmd.Execute(), "set --all should change the personal configurations")
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "[api.dev]\nAPI_TIMEOUT_MS = \"600000\"\n", string(data), "team-only configurations should be left alone")
+}
+
+func TestSetAllOnlyTeamConfigs(t *testing.T) {
+	setupTeamLayer(t, "", "[api.shared]\nAPI_TIMEOUT_MS = \"5000\"\n")
+	defer func() { valueAllFlag = false }()
+
+	namespaceFlag = ""
+	rootCmd.SetArgs([]string{"set", "--all", "-n", "api", "API_TIMEOUT_MS=600000"})
+	assert.Error(t, rootCmd.Execute(), "nothing to change should be an error")
+}
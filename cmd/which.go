@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"envpick/internal/config"
+	"envpick/internal/core"
+	"envpick/internal/text"
+)
+
+var whichFormatFlag string
+
+var whichCmd = &cobra.Command{
+	Use:   text.Text.Commands.Which.Use,
+	Short: text.Text.Commands.Which.Short,
+	Long:  text.Text.Commands.Which.Long,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := core.NewEngine()
+		if err != nil {
+			return err
+		}
+
+		matches := engine.DetectActive(core.ParseEnviron(os.Environ()))
+
+		if namespaceFlag != "" {
+			var filtered []core.NamespaceMatch
+			for _, m := range matches {
+				if m.Namespace == namespaceFlag {
+					filtered = append(filtered, m)
+				}
+			}
+			matches = filtered
+		}
+
+		switch whichFormatFlag {
+		case "json":
+			if matches == nil {
+				matches = []core.NamespaceMatch{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(matches)
+		case "table":
+			for _, m := range matches {
+				for _, u := range m.Unresolved {
+					fmt.Fprintf(os.Stderr, text.Text.Messages.WhichUnresolved, config.BuildConfigName(m.Namespace, u.Config), u.Error)
+				}
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprint(w, text.Text.Formats.WhichTableHeader)
+			for _, m := range matches {
+				detected, score := text.Text.Formats.NoMatch, ""
+				if m.Config != "" {
+					detected = m.Config
+					score = fmt.Sprintf(text.Text.Formats.WhichScore, m.Score*100, m.Matched, m.Total)
+				}
+				persisted := m.Persisted
+				if persisted == "" {
+					persisted = text.Text.Formats.NoMatch
+				}
+				fmt.Fprintf(w, text.Text.Formats.WhichTableRow, displayNamespace(m.Namespace), detected, score, persisted, strings.Join(m.Differing, ", "))
+			}
+			return w.Flush()
+		default:
+			return fmt.Errorf(text.Text.Errors.UnknownFormat, whichFormatFlag)
+		}
+	},
+}
+
+func init() {
+	whichCmd.Flags().StringVarP(&whichFormatFlag, "format", "f", "table", text.Text.Commands.Flags.StatusFormat)
+}
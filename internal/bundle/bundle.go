@@ -0,0 +1,128 @@
+package bundle
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/BurntSushi/toml"
+
+	"envpick/internal/text"
+)
+
+// Bundle is a single configuration shared with a teammate
+type Bundle struct {
+	Profile   string            `toml:"profile"`
+	Created   time.Time         `toml:"created"`
+	Values    map[string]string `toml:"values"`
+	Commands  map[string]string `toml:"commands,omitempty"` // the [name._commands] table
+	Signer    string            `toml:"signer"`             // base64 ed25519 public key of the sender
+	Signature string            `toml:"signature"`          // base64 signature over payload()
+}
+
+// payload returns the signed content of the bundle.
+// JSON encoding sorts map keys, so the result is canonical.
+func (b *Bundle) payload() ([]byte, error) {
+	// Commands are omitted when empty, so bundles without them keep their signature
+	return json.Marshal(struct {
+		Profile  string            `json:"profile"`
+		Created  time.Time         `json:"created"`
+		Values   map[string]string `json:"values"`
+		Commands map[string]string `json:"commands,omitempty"`
+	}{b.Profile, b.Created.UTC(), b.Values, b.Commands})
+}
+
+// Fingerprint returns a short, comparable form of the signer's public key
+func (b *Bundle) Fingerprint() string {
+	return Fingerprint(b.Signer)
+}
+
+// Fingerprint returns the first 16 hex characters of the SHA-256 of a public key
+func Fingerprint(publicKey string) string {
+	sum := sha256.Sum256([]byte(publicKey))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// Seal signs the bundle with key and encrypts it to recipient as an armored age file
+func Seal(b *Bundle, key ed25519.PrivateKey, recipient age.Recipient) ([]byte, error) {
+	b.Signer = base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+	payload, err := b.payload()
+	if err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.BundleSeal, err)
+	}
+	b.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+
+	var plaintext bytes.Buffer
+	if err := toml.NewEncoder(&plaintext).Encode(b); err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.BundleSeal, err)
+	}
+
+	var out bytes.Buffer
+	armorWriter := armor.NewWriter(&out)
+	w, err := age.Encrypt(armorWriter, recipient)
+	if err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.BundleSeal, err)
+	}
+	if _, err := w.Write(plaintext.Bytes()); err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.BundleSeal, err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.BundleSeal, err)
+	}
+	if err := armorWriter.Close(); err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.BundleSeal, err)
+	}
+
+	return out.Bytes(), nil
+}
+
+// Open decrypts data with identity and verifies the bundle signature
+func Open(data []byte, identity age.Identity) (*Bundle, error) {
+	r, err := age.Decrypt(armor.NewReader(bytes.NewReader(data)), identity)
+	if err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.BundleOpen, err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.BundleOpen, err)
+	}
+
+	var b Bundle
+	if _, err := toml.Decode(string(plaintext), &b); err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.BundleOpen, err)
+	}
+
+	if err := b.verify(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// verify checks the signature against the embedded signer key
+func (b *Bundle) verify() error {
+	publicKey, err := base64.StdEncoding.DecodeString(b.Signer)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return errors.New(text.Text.Errors.BundleBadSignature)
+	}
+	signature, err := base64.StdEncoding.DecodeString(b.Signature)
+	if err != nil {
+		return errors.New(text.Text.Errors.BundleBadSignature)
+	}
+	payload, err := b.payload()
+	if err != nil {
+		return fmt.Errorf(text.Text.Errors.BundleOpen, err)
+	}
+	if !ed25519.Verify(publicKey, payload, signature) {
+		return errors.New(text.Text.Errors.BundleBadSignature)
+	}
+	return nil
+}
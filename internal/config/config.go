@@ -2,26 +2,73 @@ package config
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 
+	"envpick/internal/fsutil"
+	"envpick/internal/resolve"
 	"envpick/internal/text"
+	"envpick/internal/tomledit"
 )
 
 // Config represents the main configuration file
 type Config struct {
 	Configs map[string]map[string]string `toml:"-"`
+
+	// Sources maps config name -> key -> layer the value came from (LayerTeam or LayerPersonal).
+	// Commands are listed as _commands.<name>, as in Rejected
+	Sources map[string]map[string]string `toml:"-"`
+
+	// Rejected maps config name -> personal keys ignored because the team layer does not allow overriding them
+	Rejected map[string][]string `toml:"-"`
+
+	// Commands maps config name -> command name -> shell command (from the [name._commands] table)
+	Commands map[string]map[string]string `toml:"-"`
 }
 
 // ConfigEntry represents a single configuration with its variables and metadata
 type ConfigEntry struct {
 	Vars   map[string]string
 	WebURL string
+	// CacheTTL enables caching of resolved provider references (from _cache_ttl)
+	CacheTTL time.Duration
+	// SecretKeys lists keys explicitly marked as secret (from _secret_keys)
+	SecretKeys map[string]bool
+	// Metadata holds all metadata keys (_ prefix) and their raw values
+	Metadata map[string]string
+	// Commands maps command names to shell commands (from the [name._commands] table)
+	Commands map[string]string
+	// OnActivate and OnDeactivate are shell commands run when switching to and
+	// away from the configuration (from _on_activate and _on_deactivate)
+	OnActivate   string
+	OnDeactivate string
+	// HookFailure is HookAbort or HookWarn (from _hook_failure, default abort)
+	HookFailure string
+	// HookTimeout limits how long a hook may run (from _hook_timeout)
+	HookTimeout time.Duration
+	// Protected configurations must be confirmed before activation (from _protected or _confirm)
+	Protected bool
+	// RequireReason asks for a reason, recorded in the audit log, when switching
+	// to the configuration (from _require_reason)
+	RequireReason bool
 }
 
+// Hook failure policies (_hook_failure)
+const (
+	HookAbort = "abort" // a failing hook cancels the switch
+	HookWarn  = "warn"  // a failing hook is reported and the switch goes ahead
+)
+
+// DefaultHookTimeout applies to configurations without _hook_timeout
+const DefaultHookTimeout = 30 * time.Second
+
 // GetConfigDir returns the envpick configuration directory
 func GetConfigDir() (string, error) {
 	home, err := os.UserHomeDir()
@@ -40,44 +87,88 @@ func GetConfigPath() (string, error) {
 	return filepath.Join(dir, "config.toml"), nil
 }
 
-// LoadConfig loads the configuration from config.toml
+// LoadConfig loads the configuration from config.toml, layered over the
+// signed team config when one is present
 func LoadConfig() (*Config, error) {
+	return loadConfig(false)
+}
+
+// LoadConfigOrEmpty is like LoadConfig, but a missing config.toml is read as
+// an empty file, for commands that create the first configuration. Every
+// other error, including one of the team layer, is still returned.
+func LoadConfigOrEmpty() (*Config, error) {
+	return loadConfig(true)
+}
+
+// loadConfig loads the layered configuration; allowMissing accepts a missing config.toml
+func loadConfig(allowMissing bool) (*Config, error) {
 	configPath, err := GetConfigPath()
 	if err != nil {
 		return nil, err
 	}
 
+	team, err := loadTeamLayer()
+	if err != nil {
+		return nil, err
+	}
+
 	// Read raw TOML
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		if os.IsNotExist(err) {
+		// The team layer alone is a valid configuration
+		if os.IsNotExist(err) && (team != nil || allowMissing) {
+			data = nil
+		} else if os.IsNotExist(err) {
 			return nil, fmt.Errorf(text.Text.Errors.ConfigFileNotFound, configPath)
+		} else {
+			return nil, fmt.Errorf(text.Text.Errors.ConfigFileRead, err)
 		}
-		return nil, fmt.Errorf(text.Text.Errors.ConfigFileRead, err)
+	}
+
+	personal, err := parseConfigs(data)
+	if err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.ConfigFileParse, err)
+	}
+
+	return mergeLayers(team, personal), nil
+}
+
+// parseConfigs extracts config sections and their commands from raw TOML data
+func parseConfigs(data []byte) (*Config, error) {
+	// Older formats are upgraded in memory; 'envpick migrate' rewrites the file
+	data, _, err := MigrateConfig(data)
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse into generic map first
 	var raw map[string]interface{}
 	if _, err := toml.Decode(string(data), &raw); err != nil {
-		return nil, fmt.Errorf(text.Text.Errors.ConfigFileParse, err)
+		return nil, err
 	}
 
-	config := &Config{
-		Configs: make(map[string]map[string]string),
+	cfg := &Config{
+		Configs:  make(map[string]map[string]string),
+		Commands: make(map[string]map[string]string),
 	}
 
 	// Extract config sections (recursively handle nested tables)
-	extractConfigs(config.Configs, raw, "")
+	extractConfigs(cfg, raw, "")
 
-	return config, nil
+	return cfg, nil
 }
 
-// extractConfigs recursively extracts configuration sections from TOML data
+// ReservedName is never read as a configuration or namespace, at any level:
+// early versions used a top-level default key, and [default] tables written
+// alongside it were ignored
+const ReservedName = "default"
+
+// extractConfigs recursively extracts configuration sections from TOML data into cfg
 // prefix is used to build the full config name (e.g., "db" for nested tables)
-func extractConfigs(configs map[string]map[string]string, data map[string]interface{}, prefix string) {
+func extractConfigs(cfg *Config, data map[string]interface{}, prefix string) {
 	for key, val := range data {
-		if key == "default" {
-			continue // Skip legacy default key
+		if key == ReservedName {
+			continue // Early versions' default key, and tables sharing its name
 		}
 
 		fullKey := key
@@ -86,35 +177,55 @@ func extractConfigs(configs map[string]map[string]string, data map[string]interf
 		}
 
 		if section, ok := val.(map[string]interface{}); ok {
-			// Check if this is a config section (contains string values) or a namespace (contains nested maps)
-			hasStringValues := false
+			// Check if this is a config section or a namespace (contains nested maps).
+			// Metadata subtables such as [work._commands] belong to the config.
+			// An empty section is a config without variables yet
 			hasNestedMaps := false
 
-			for _, v := range section {
-				if _, ok := v.(string); ok {
-					hasStringValues = true
-				}
-				if _, ok := v.(map[string]interface{}); ok {
+			for k, v := range section {
+				if _, ok := v.(map[string]interface{}); ok && !strings.HasPrefix(k, "_") {
 					hasNestedMaps = true
 				}
 			}
 
-			if hasStringValues && !hasNestedMaps {
+			if !hasNestedMaps {
 				// This is a config section
-				configs[fullKey] = make(map[string]string)
+				cfg.Configs[fullKey] = make(map[string]string)
 				for k, v := range section {
-					if s, ok := v.(string); ok {
-						configs[fullKey][k] = s
+					switch v := v.(type) {
+					case string:
+						cfg.Configs[fullKey][k] = v
+					case map[string]interface{}:
+						if k != CommandsTable {
+							continue // Unknown metadata subtable
+						}
+						commands := make(map[string]string)
+						for command, sv := range v {
+							if s, ok := sv.(string); ok {
+								commands[command] = s
+							}
+						}
+						cfg.Commands[fullKey] = commands
 					}
 				}
-			} else if hasNestedMaps {
+			} else {
 				// This is a namespace, recurse into it
-				extractConfigs(configs, section, fullKey)
+				extractConfigs(cfg, section, fullKey)
 			}
 		}
 	}
 }
 
+// CommandsTable is the metadata subtable holding a configuration's named
+// commands: [name._commands]
+const CommandsTable = "_commands"
+
+// IsCommandKey reports whether a metadata key holds a shell command that envpick runs.
+// The commands of the [name._commands] table are kept apart, in Config.Commands.
+func IsCommandKey(key string) bool {
+	return key == "_on_activate" || key == "_on_deactivate"
+}
+
 // GetEntry returns a ConfigEntry for the given config name
 func (c *Config) GetEntry(name string) (*ConfigEntry, error) {
 	vars, ok := c.Configs[name]
@@ -123,13 +234,60 @@ func (c *Config) GetEntry(name string) (*ConfigEntry, error) {
 	}
 
 	entry := &ConfigEntry{
-		Vars: make(map[string]string),
+		Vars:        make(map[string]string),
+		SecretKeys:  make(map[string]bool),
+		Metadata:    make(map[string]string),
+		Commands:    make(map[string]string),
+		HookFailure: HookAbort,
+		HookTimeout: DefaultHookTimeout,
 	}
+	maps.Copy(entry.Commands, c.Commands[name])
 
 	for k, v := range vars {
+		if strings.HasPrefix(k, "_") {
+			entry.Metadata[k] = v
+		}
+
 		switch k {
 		case "_web_url":
 			entry.WebURL = v
+		case "_cache_ttl":
+			ttl, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf(text.Text.Errors.ConfigInvalidCacheTTL, name, err)
+			}
+			entry.CacheTTL = ttl
+		case "_secret_keys":
+			for _, key := range strings.Split(v, ",") {
+				if key = strings.TrimSpace(key); key != "" {
+					entry.SecretKeys[key] = true
+				}
+			}
+		case "_on_activate":
+			entry.OnActivate = v
+		case "_on_deactivate":
+			entry.OnDeactivate = v
+		case "_hook_failure":
+			if v != HookAbort && v != HookWarn {
+				return nil, fmt.Errorf(text.Text.Errors.ConfigInvalidHookFailure, name, v)
+			}
+			entry.HookFailure = v
+		case "_hook_timeout":
+			timeout, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf(text.Text.Errors.ConfigInvalidHookTimeout, name, err)
+			}
+			entry.HookTimeout = timeout
+		case "_protected", "_confirm", "_require_reason":
+			flag, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf(text.Text.Errors.ConfigInvalidBool, name, k, v)
+			}
+			if k == "_require_reason" {
+				entry.RequireReason = flag
+			} else {
+				entry.Protected = entry.Protected || flag
+			}
 		default:
 			if len(k) > 0 && k[0] != '_' {
 				entry.Vars[k] = v
@@ -140,12 +298,13 @@ func (c *Config) GetEntry(name string) (*ConfigEntry, error) {
 	return entry, nil
 }
 
-// GetConfigNames returns all configuration names
+// GetConfigNames returns all configuration names, sorted
 func (c *Config) GetConfigNames() []string {
 	var names []string
 	for name := range c.Configs {
 		names = append(names, name)
 	}
+	sort.Strings(names)
 	return names
 }
 
@@ -182,7 +341,7 @@ func (c *Config) GetNamespaceConfigs(namespace string) map[string]map[string]str
 	return result
 }
 
-// GetNamespaces returns a list of all unique namespaces in the config.
+// GetNamespaces returns a sorted list of all unique namespaces in the config.
 func (c *Config) GetNamespaces() []string {
 	namespaceSet := make(map[string]bool)
 	for fullName := range c.Configs {
@@ -194,19 +353,113 @@ func (c *Config) GetNamespaces() []string {
 	for ns := range namespaceSet {
 		namespaces = append(namespaces, ns)
 	}
+	sort.Strings(namespaces)
 	return namespaces
 }
 
-// GetExportStatements returns shell export statements for a configuration
-func (c *Config) GetExportStatements(name string) ([]string, error) {
+// GetResolvedVars returns the variables of each named configuration with provider
+// references resolved. References across all configurations are resolved concurrently.
+func (c *Config) GetResolvedVars(names ...string) (map[string]map[string]string, error) {
+	type slot struct {
+		name string
+		key  string
+	}
+
+	var (
+		jobs  []resolve.Job
+		slots []slot
+	)
+	for _, name := range names {
+		entry, err := c.GetEntry(name)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range entry.Vars {
+			jobs = append(jobs, resolve.Job{Value: v, TTL: entry.CacheTTL})
+			slots = append(slots, slot{name: name, key: k})
+		}
+	}
+
+	resolver, err := NewResolver()
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := resolver.Resolve(jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]map[string]string)
+	for _, name := range names {
+		resolved[name] = make(map[string]string)
+	}
+	for i, s := range slots {
+		resolved[s.name][s.key] = values[i]
+	}
+
+	return resolved, nil
+}
+
+// ResolveVars returns the given variables of one configuration with provider
+// references resolved, leaving the references of its other variables untouched
+func (c *Config) ResolveVars(name string, keys []string) (map[string]string, error) {
 	entry, err := c.GetEntry(name)
 	if err != nil {
 		return nil, err
 	}
 
+	jobs := make([]resolve.Job, len(keys))
+	for i, k := range keys {
+		jobs[i] = resolve.Job{Value: entry.Vars[k], TTL: entry.CacheTTL}
+	}
+
+	resolver, err := NewResolver()
+	if err != nil {
+		return nil, err
+	}
+	values, err := resolver.Resolve(jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]string, len(keys))
+	for i, k := range keys {
+		resolved[k] = values[i]
+	}
+	return resolved, nil
+}
+
+// NewResolver returns the resolver used for provider references.
+// This is a variable to allow overriding in tests
+var NewResolver = func() (*resolve.Resolver, error) {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &resolve.Resolver{Cache: resolve.NewCache(dir)}, nil
+}
+
+// GetExportStatements returns shell export statements for one or more configurations.
+// Later configurations win when several set the same variable.
+func (c *Config) GetExportStatements(names ...string) ([]string, error) {
+	resolved, err := c.GetResolvedVars(names...)
+	if err != nil {
+		return nil, err
+	}
+
 	var exports []string
-	for k, v := range entry.Vars {
-		exports = append(exports, fmt.Sprintf(text.Text.Formats.ExportStatement, k, v))
+	for _, name := range names {
+		vars := resolved[name]
+		keys := make([]string, 0, len(vars))
+		for k := range vars {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			exports = append(exports, fmt.Sprintf(text.Text.Formats.ExportStatement, k, vars[k]))
+		}
 	}
 
 	return exports, nil
@@ -226,6 +479,44 @@ func (c *Config) GetWebURL(name string) (string, error) {
 	return entry.WebURL, nil
 }
 
+// UpdateConfigFile applies edit to config.toml, preserving comments and layout.
+// The file is created if it does not exist and replaced atomically.
+func UpdateConfigFile(edit func(doc *tomledit.Document) error) error {
+	if err := EnsureConfigDir(); err != nil {
+		return err
+	}
+
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0600)
+	data, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf(text.Text.Errors.ConfigFileRead, err)
+	}
+	if info, statErr := os.Stat(configPath); statErr == nil {
+		mode = info.Mode().Perm()
+	}
+
+	doc := tomledit.Parse(data)
+	if err := edit(doc); err != nil {
+		return err
+	}
+
+	// Never write a file that LoadConfig could not read back
+	var raw map[string]interface{}
+	if _, err := toml.Decode(string(doc.Bytes()), &raw); err != nil {
+		return fmt.Errorf(text.Text.Errors.ConfigFileParse, err)
+	}
+
+	if err := fsutil.WriteFileAtomic(configPath, doc.Bytes(), mode); err != nil {
+		return fmt.Errorf(text.Text.Errors.ConfigFileWrite, err)
+	}
+	return nil
+}
+
 // EnsureConfigDir creates the config directory if it doesn't exist
 func EnsureConfigDir() error {
 	dir, err := GetConfigDir()
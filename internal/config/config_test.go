@@ -1,9 +1,14 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"envpick/internal/tomledit"
 )
 
 func TestParseConfigName(t *testing.T) {
@@ -194,20 +199,104 @@ func TestExtractConfigs(t *testing.T) {
 			expectedKeys:   []string{"dev", "db.local"},
 			unexpectedKeys: []string{"db"},
 		},
+		{
+			name: "metadata subtable",
+			input: map[string]interface{}{
+				"work": map[string]interface{}{
+					"_commands": map[string]interface{}{
+						"console": "psql",
+					},
+				},
+			},
+			expectedKeys:   []string{"work"},
+			unexpectedKeys: []string{"work._commands"},
+		},
+		{
+			name: "empty config",
+			input: map[string]interface{}{
+				"scratch": map[string]interface{}{},
+				"db": map[string]interface{}{
+					"local": map[string]interface{}{},
+				},
+			},
+			expectedKeys:   []string{"scratch", "db.local"},
+			unexpectedKeys: []string{"db"},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			configs := make(map[string]map[string]string)
-			extractConfigs(configs, tt.input, "")
+			cfg := &Config{
+				Configs:  make(map[string]map[string]string),
+				Commands: make(map[string]map[string]string),
+			}
+			extractConfigs(cfg, tt.input, "")
 
 			for _, key := range tt.expectedKeys {
-				assert.Contains(t, configs, key, "should contain expected key")
+				assert.Contains(t, cfg.Configs, key, "should contain expected key")
 			}
 
 			for _, key := range tt.unexpectedKeys {
-				assert.NotContains(t, configs, key, "should not contain unexpected key")
+				assert.NotContains(t, cfg.Configs, key, "should not contain unexpected key")
 			}
 		})
 	}
 }
+
+func TestParseConfigsCommands(t *testing.T) {
+	data := `
+[db.staging]
+DATABASE_URL = "postgres://staging"
+
+"_commands.migrate" = "not a command"
+
+[db.staging._commands]
+console = "psql $DATABASE_URL"
+logs = "kubectl logs -f deploy/api"
+
+[db.staging._other]
+ignored = "true"
+`
+	cfg, err := parseConfigs([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"DATABASE_URL":      "postgres://staging",
+		"_commands.migrate": "not a command",
+	}, cfg.Configs["db.staging"], "subtables should not be flattened into keys")
+
+	entry, err := cfg.GetEntry("db.staging")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DATABASE_URL": "postgres://staging"}, entry.Vars, "commands are not variables")
+	assert.Equal(t, map[string]string{
+		"console": "psql $DATABASE_URL",
+		"logs":    "kubectl logs -f deploy/api",
+	}, entry.Commands)
+}
+
+func TestUpdateConfigFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	configPath, err := GetConfigPath()
+	require.NoError(t, err)
+	require.NoError(t, EnsureConfigDir())
+	require.NoError(t, os.WriteFile(configPath, []byte("# keep\n[dev]\nA = \"1\"\n"), 0640))
+
+	err = UpdateConfigFile(func(doc *tomledit.Document) error {
+		doc.SetValue("dev", "A", "2")
+		return nil
+	})
+	require.NoError(t, err, "UpdateConfigFile should succeed")
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# keep\n[dev]\nA = \"2\"\n", string(data))
+
+	info, err := os.Stat(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm(), "the file mode should be kept")
+
+	entries, err := os.ReadDir(filepath.Dir(configPath))
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Name(), ".tmp", "no temporary file should be left behind")
+	}
+}
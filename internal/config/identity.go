@@ -0,0 +1,178 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+
+	"envpick/internal/text"
+)
+
+// GetIdentityPath returns the path to the age identity used to receive bundles
+func GetIdentityPath() (string, error) {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "identity.txt"), nil
+}
+
+// GetSigningKeyPath returns the path to the ed25519 key used to sign bundles
+func GetSigningKeyPath() (string, error) {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "signing.key"), nil
+}
+
+// LoadOrCreateIdentity returns the local age identity, generating it on first use
+func LoadOrCreateIdentity() (*age.X25519Identity, error) {
+	path, err := GetIdentityPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		identity, err := age.ParseX25519Identity(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf(text.Text.Errors.IdentityParse, path, err)
+		}
+		return identity, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf(text.Text.Errors.IdentityRead, err)
+	}
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.IdentityCreate, err)
+	}
+	if err := writePrivateFile(path, identity.String()+"\n"); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// LoadOrCreateSigningKey returns the local ed25519 signing key, generating it on first use
+func LoadOrCreateSigningKey() (ed25519.PrivateKey, error) {
+	path, err := GetSigningKeyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf(text.Text.Errors.IdentityParse, path, err)
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf(text.Text.Errors.IdentityRead, err)
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.IdentityCreate, err)
+	}
+	if err := writePrivateFile(path, base64.StdEncoding.EncodeToString(key.Seed())+"\n"); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// GetTrustedSignersPath returns the path to the fingerprints of bundle signers
+// that 'envpick receive' accepts without asking
+func GetTrustedSignersPath() (string, error) {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "trusted_signers"), nil
+}
+
+// IsTrustedSigner reports whether fingerprint is listed in the trusted signers
+// file, one fingerprint per line; # starts a comment
+func IsTrustedSigner(fingerprint string) (bool, error) {
+	path, err := GetTrustedSignersPath()
+	if err != nil {
+		return false, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf(text.Text.Errors.TrustedSignersRead, err)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if strings.TrimSpace(line) == fingerprint {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// TrustSigner adds fingerprint to the trusted signers file, with name as a comment
+func TrustSigner(fingerprint, name string) error {
+	path, err := GetTrustedSignersPath()
+	if err != nil {
+		return err
+	}
+	if err := EnsureConfigDir(); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf(text.Text.Errors.TrustedSignersWrite, err)
+	}
+	_, err = fmt.Fprintf(f, "%s # %s\n", fingerprint, name)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf(text.Text.Errors.TrustedSignersWrite, err)
+	}
+	return nil
+}
+
+// EncodePublicKey returns the text form of an ed25519 public key
+func EncodePublicKey(key ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// DecodePublicKey parses the text form of an ed25519 public key
+func DecodePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.PublicKeyInvalid, err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf(text.Text.Errors.PublicKeyInvalid, fmt.Errorf("expected %d bytes, got %d", ed25519.PublicKeySize, len(key)))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// writePrivateFile creates a file readable only by the current user
+func writePrivateFile(path, content string) error {
+	if err := EnsureConfigDir(); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return fmt.Errorf(text.Text.Errors.IdentityCreate, err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedSigners(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	trusted, err := IsTrustedSigner("5a566f23e8329623")
+	require.NoError(t, err, "a missing file trusts nobody")
+	assert.False(t, trusted)
+
+	path := filepath.Join(home, ".envpick", "trusted_signers")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, []byte("# alice\n0123456789abcdef\n"), 0600))
+
+	require.NoError(t, TrustSigner("5a566f23e8329623", "work"))
+	for _, fingerprint := range []string{"0123456789abcdef", "5a566f23e8329623"} {
+		trusted, err = IsTrustedSigner(fingerprint)
+		require.NoError(t, err)
+		assert.True(t, trusted, fingerprint)
+	}
+
+	trusted, err = IsTrustedSigner("alice")
+	require.NoError(t, err)
+	assert.False(t, trusted, "comments are not fingerprints")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# alice\n0123456789abcdef\n5a566f23e8329623 # work\n", string(data))
+}
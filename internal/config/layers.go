@@ -0,0 +1,236 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"envpick/internal/text"
+)
+
+// Layer names reported in Config.Sources
+const (
+	LayerTeam     = "team"
+	LayerPersonal = "personal"
+)
+
+// overridableKey lists, in a team config, the keys personal config may override
+const overridableKey = "_overridable"
+
+// teamSignatureSuffix is appended to the team config path to locate its signature
+const teamSignatureSuffix = ".sig"
+
+// DefaultTeamConfigPath is used when ENVPICK_TEAM_CONFIG is not set
+const DefaultTeamConfigPath = "/etc/envpick/team.toml"
+
+// GetTeamConfigPath returns the path to the team config and whether it was set explicitly.
+// This is a variable to allow overriding in tests
+var GetTeamConfigPath = func() (string, bool) {
+	if path := os.Getenv("ENVPICK_TEAM_CONFIG"); path != "" {
+		return path, true
+	}
+	return DefaultTeamConfigPath, false
+}
+
+// GetTrustedKeysPath returns the path to the list of public keys trusted to sign team configs
+func GetTrustedKeysPath() (string, error) {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "team_keys"), nil
+}
+
+// warnTeamLayerIgnored prints the missing team_keys warning once per process
+var warnTeamLayerIgnored sync.Once
+
+// loadTeamLayer returns the configs of the team layer after verifying its signature.
+// Returns nil when no team config exists, or when no key is trusted to verify it.
+func loadTeamLayer() (*Config, error) {
+	path, explicit := GetTeamConfigPath()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil, nil
+		}
+		return nil, fmt.Errorf(text.Text.Errors.TeamConfigRead, err)
+	}
+
+	keys, err := loadTrustedKeys()
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		// Nothing can be verified yet; keep the personal configs usable
+		warnTeamLayerIgnored.Do(func() {
+			keysPath, _ := GetTrustedKeysPath()
+			fmt.Fprintf(os.Stderr, text.Text.Messages.TeamLayerIgnored, path, keysPath)
+		})
+		return nil, nil
+	}
+
+	signature, err := os.ReadFile(path + teamSignatureSuffix)
+	if err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.TeamConfigUnsigned, path)
+	}
+
+	if err := verifyTeamConfig(data, signature, keys); err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.TeamConfigVerify, path, err)
+	}
+
+	cfg, err := parseConfigs(data)
+	if err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.TeamConfigParse, err)
+	}
+	return cfg, nil
+}
+
+// loadTrustedKeys reads the trusted public keys, one per line; # starts a comment
+func loadTrustedKeys() ([]ed25519.PublicKey, error) {
+	path, err := GetTrustedKeysPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf(text.Text.Errors.TrustedKeysRead, err)
+	}
+
+	var keys []ed25519.PublicKey
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		key, err := DecodePublicKey(line)
+		if err != nil {
+			return nil, fmt.Errorf(text.Text.Errors.TrustedKeysRead, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// verifyTeamConfig checks that signature is a valid signature of data by one of keys
+func verifyTeamConfig(data, signature []byte, keys []ed25519.PublicKey) error {
+	if len(keys) == 0 {
+		return errors.New(text.Text.Errors.TeamConfigNoTrustedKeys)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return errors.New(text.Text.Errors.TeamConfigBadSignature)
+	}
+
+	for _, key := range keys {
+		if ed25519.Verify(key, data, sig) {
+			return nil
+		}
+	}
+	return errors.New(text.Text.Errors.TeamConfigBadSignature)
+}
+
+// SignTeamConfig signs the team config at path with key and writes the signature
+// next to it. Returns the path of the signature file.
+func SignTeamConfig(path string, key ed25519.PrivateKey) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf(text.Text.Errors.TeamConfigRead, err)
+	}
+
+	sigPath := path + teamSignatureSuffix
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(key, data))
+	if err := os.WriteFile(sigPath, []byte(signature+"\n"), 0644); err != nil {
+		return "", fmt.Errorf(text.Text.Errors.TeamConfigSign, err)
+	}
+	return sigPath, nil
+}
+
+// mergeLayers layers personal configs over team configs; team may be nil.
+// Within a team config, personal values replace only the keys listed in _overridable
+// ("*" allows all); keys the team config does not define may always be added.
+// Commands follow the same rule, with "_commands" allowing the whole table.
+func mergeLayers(team, personal *Config) *Config {
+	if team == nil {
+		team = &Config{}
+	}
+	config := &Config{
+		Configs:  make(map[string]map[string]string),
+		Sources:  make(map[string]map[string]string),
+		Rejected: make(map[string][]string),
+		Commands: make(map[string]map[string]string),
+	}
+
+	for name, vars := range team.Configs {
+		config.Configs[name] = make(map[string]string)
+		config.Sources[name] = make(map[string]string)
+		for k, v := range vars {
+			config.Configs[name][k] = v
+			config.Sources[name][k] = LayerTeam
+		}
+	}
+	for name, commands := range team.Commands {
+		config.Commands[name] = maps.Clone(commands)
+		for command := range commands {
+			config.Sources[name][CommandsTable+"."+command] = LayerTeam
+		}
+	}
+
+	for name, vars := range personal.Configs {
+		if _, ok := config.Configs[name]; !ok {
+			config.Configs[name] = make(map[string]string)
+			config.Sources[name] = make(map[string]string)
+		}
+
+		allowed := overridableKeys(team.Configs[name])
+		for k, v := range vars {
+			if _, defined := team.Configs[name][k]; defined && !allowed["*"] && !allowed[k] {
+				config.Rejected[name] = append(config.Rejected[name], k)
+				continue
+			}
+			config.Configs[name][k] = v
+			config.Sources[name][k] = LayerPersonal
+		}
+
+		for command, v := range personal.Commands[name] {
+			if _, defined := team.Commands[name][command]; defined && !allowed["*"] && !allowed[CommandsTable] {
+				config.Rejected[name] = append(config.Rejected[name], CommandsTable+"."+command)
+				continue
+			}
+			if config.Commands[name] == nil {
+				config.Commands[name] = make(map[string]string)
+			}
+			config.Commands[name][command] = v
+			config.Sources[name][CommandsTable+"."+command] = LayerPersonal
+		}
+		sort.Strings(config.Rejected[name])
+	}
+
+	return config
+}
+
+// overridableKeys parses the comma-separated _overridable list of a team config
+func overridableKeys(vars map[string]string) map[string]bool {
+	allowed := make(map[string]bool)
+	for _, key := range strings.Split(vars[overridableKey], ",") {
+		if key = strings.TrimSpace(key); key != "" && key != overridableKey {
+			allowed[key] = true
+		}
+	}
+	return allowed
+}
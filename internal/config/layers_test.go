@@ -0,0 +1,205 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const teamConfig = `
+[work]
+ANTHROPIC_BASE_URL = "https://api.company.com"
+ANTHROPIC_MODEL = "claude-sonnet-4-5"
+_overridable = "ANTHROPIC_MODEL"
+
+[shared]
+REGION = "us-east-1"
+_overridable = "*"
+`
+
+const personalConfig = `
+[work]
+ANTHROPIC_BASE_URL = "https://evil.example.com"
+ANTHROPIC_MODEL = "claude-opus-4-5"
+ANTHROPIC_AUTH_TOKEN = "sk-mine"
+
+[shared]
+REGION = "eu-west-1"
+
+[personal]
+ANTHROPIC_API_KEY = "sk-personal"
+`
+
+// setupLayers writes team and personal configs into a temporary HOME and returns
+// the team config path and the signing key trusted for it
+func setupLayers(t *testing.T) (string, ed25519.PrivateKey) {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configDir := filepath.Join(home, ".envpick")
+	require.NoError(t, os.MkdirAll(configDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(personalConfig), 0644))
+
+	teamPath := filepath.Join(t.TempDir(), "team.toml")
+	require.NoError(t, os.WriteFile(teamPath, []byte(teamConfig), 0644))
+	t.Setenv("ENVPICK_TEAM_CONFIG", teamPath)
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "team_keys"), []byte("# team\n"+EncodePublicKey(public)+"\n"), 0644))
+
+	return teamPath, private
+}
+
+func TestLoadConfigWithTeamLayer(t *testing.T) {
+	teamPath, key := setupLayers(t)
+	_, err := SignTeamConfig(teamPath, key)
+	require.NoError(t, err, "SignTeamConfig should succeed")
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err, "LoadConfig should succeed")
+
+	// Non-overridable team value wins
+	assert.Equal(t, "https://api.company.com", cfg.Configs["work"]["ANTHROPIC_BASE_URL"])
+	assert.Equal(t, LayerTeam, cfg.Sources["work"]["ANTHROPIC_BASE_URL"])
+	assert.Equal(t, []string{"ANTHROPIC_BASE_URL"}, cfg.Rejected["work"])
+
+	// Overridable value and personal-only keys come from personal config
+	assert.Equal(t, "claude-opus-4-5", cfg.Configs["work"]["ANTHROPIC_MODEL"])
+	assert.Equal(t, LayerPersonal, cfg.Sources["work"]["ANTHROPIC_MODEL"])
+	assert.Equal(t, LayerPersonal, cfg.Sources["work"]["ANTHROPIC_AUTH_TOKEN"])
+
+	// Wildcard allows every override
+	assert.Equal(t, "eu-west-1", cfg.Configs["shared"]["REGION"])
+	assert.Empty(t, cfg.Rejected["shared"])
+
+	assert.Equal(t, LayerPersonal, cfg.Sources["personal"]["ANTHROPIC_API_KEY"])
+}
+
+func TestLoadConfigRejectsTamperedTeamLayer(t *testing.T) {
+	teamPath, key := setupLayers(t)
+	_, err := SignTeamConfig(teamPath, key)
+	require.NoError(t, err, "SignTeamConfig should succeed")
+
+	// Tamper with the base URL after signing
+	tampered := []byte(teamConfig + "\n[work2]\nANTHROPIC_BASE_URL = \"https://evil.example.com\"\n")
+	require.NoError(t, os.WriteFile(teamPath, tampered, 0644))
+
+	_, err = LoadConfig()
+	require.Error(t, err, "tampered team config should be rejected")
+	assert.Contains(t, err.Error(), "signature")
+}
+
+func TestLoadConfigRejectsUntrustedSigner(t *testing.T) {
+	teamPath, _ := setupLayers(t)
+
+	_, other, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = SignTeamConfig(teamPath, other)
+	require.NoError(t, err, "SignTeamConfig should succeed")
+
+	_, err = LoadConfig()
+	assert.Error(t, err, "team config signed by an untrusted key should be rejected")
+}
+
+func TestLoadConfigRejectsUnsignedTeamLayer(t *testing.T) {
+	setupLayers(t)
+
+	_, err := LoadConfig()
+	require.Error(t, err, "unsigned team config should be rejected")
+	assert.Contains(t, err.Error(), "no signature")
+}
+
+func TestLoadConfigTeamLayerOnly(t *testing.T) {
+	teamPath, key := setupLayers(t)
+	_, err := SignTeamConfig(teamPath, key)
+	require.NoError(t, err, "SignTeamConfig should succeed")
+
+	home, _ := os.UserHomeDir()
+	require.NoError(t, os.Remove(filepath.Join(home, ".envpick", "config.toml")))
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err, "team config alone should load")
+	assert.Equal(t, "claude-sonnet-4-5", cfg.Configs["work"]["ANTHROPIC_MODEL"])
+}
+
+func TestLoadConfigIgnoresTeamLayerWithoutTrustedKeys(t *testing.T) {
+	teamPath, key := setupLayers(t)
+	_, err := SignTeamConfig(teamPath, key)
+	require.NoError(t, err, "SignTeamConfig should succeed")
+
+	keysPath, err := GetTrustedKeysPath()
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(keysPath))
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err, "a missing team_keys should not break personal configs")
+	assert.Equal(t, "https://evil.example.com", cfg.Configs["work"]["ANTHROPIC_BASE_URL"], "the team layer should be ignored")
+	assert.Equal(t, LayerPersonal, cfg.Sources["work"]["ANTHROPIC_BASE_URL"])
+	assert.Empty(t, cfg.Rejected)
+}
+
+func TestMergeLayersAddsUndefinedKeys(t *testing.T) {
+	team := &Config{Configs: map[string]map[string]string{
+		"work": {"ANTHROPIC_BASE_URL": "https://api.company.com", overridableKey: "ANTHROPIC_MODEL"},
+	}}
+	personal := &Config{Configs: map[string]map[string]string{
+		"work": {"ANTHROPIC_BASE_URL": "https://evil.example.com", "ANTHROPIC_AUTH_TOKEN": "sk-mine"},
+	}}
+
+	cfg := mergeLayers(team, personal)
+
+	// A restrictive _overridable protects the keys the team defines, but each
+	// user still adds their own, such as credentials
+	assert.Equal(t, "https://api.company.com", cfg.Configs["work"]["ANTHROPIC_BASE_URL"])
+	assert.Equal(t, []string{"ANTHROPIC_BASE_URL"}, cfg.Rejected["work"])
+	assert.Equal(t, "sk-mine", cfg.Configs["work"]["ANTHROPIC_AUTH_TOKEN"])
+	assert.Equal(t, LayerPersonal, cfg.Sources["work"]["ANTHROPIC_AUTH_TOKEN"])
+}
+
+func TestMergeLayersCommands(t *testing.T) {
+	team := &Config{
+		Configs:  map[string]map[string]string{"work": {overridableKey: "ANTHROPIC_MODEL"}},
+		Commands: map[string]map[string]string{"work": {"deploy": "make deploy"}},
+	}
+	personal := &Config{
+		Configs:  map[string]map[string]string{"work": {}},
+		Commands: map[string]map[string]string{"work": {"deploy": "rm -rf /", "console": "psql"}},
+	}
+
+	cfg := mergeLayers(team, personal)
+	assert.Equal(t, map[string]string{"deploy": "make deploy", "console": "psql"}, cfg.Commands["work"])
+	assert.Equal(t, []string{"_commands.deploy"}, cfg.Rejected["work"])
+	assert.Equal(t, LayerTeam, cfg.Sources["work"]["_commands.deploy"])
+	assert.Equal(t, LayerPersonal, cfg.Sources["work"]["_commands.console"])
+
+	// "_commands" makes the whole table overridable
+	team.Configs["work"][overridableKey] = "_commands"
+	cfg = mergeLayers(team, personal)
+	assert.Equal(t, "rm -rf /", cfg.Commands["work"]["deploy"])
+	assert.Empty(t, cfg.Rejected["work"])
+}
+
+func TestLoadConfigOrEmpty(t *testing.T) {
+	setupLayers(t)
+	home, _ := os.UserHomeDir()
+	require.NoError(t, os.Remove(filepath.Join(home, ".envpick", "config.toml")))
+
+	// A missing config.toml is tolerated, a team layer that fails to verify is not
+	_, err := LoadConfigOrEmpty()
+	require.Error(t, err, "unsigned team config should still be rejected")
+	assert.Contains(t, err.Error(), "no signature")
+
+	t.Setenv("ENVPICK_TEAM_CONFIG", "")
+	_, err = LoadConfig()
+	require.Error(t, err, "LoadConfig should still require config.toml")
+	cfg, err := LoadConfigOrEmpty()
+	require.NoError(t, err, "a missing config.toml should be empty")
+	assert.Empty(t, cfg.Configs)
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+
+	"envpick/internal/text"
+	"envpick/internal/tomledit"
+)
+
+// StateVersion is the state.toml format written by this version of envpick
+const StateVersion = 1
+
+// ConfigVersion is the config.toml format understood by this version of envpick
+const ConfigVersion = 1
+
+// MigrationStep describes a migration from format Version-1 to Version
+type MigrationStep struct {
+	Version     int
+	Description string
+}
+
+// stateMigration upgrades decoded state.toml data by one version
+type stateMigration struct {
+	MigrationStep
+	apply func(raw map[string]interface{})
+}
+
+// configMigration upgrades config.toml by one version, editing the document
+// in place so that comments and layout are kept
+type configMigration struct {
+	MigrationStep
+	apply func(doc *tomledit.Document)
+}
+
+// The registries are ordered by version; a format change appends a migration
+// and bumps StateVersion or ConfigVersion
+var (
+	stateMigrations = []stateMigration{
+		{MigrationStep{1, text.Text.Messages.MigrateStateCurrent}, migrateCurrentConfig},
+	}
+	configMigrations = []configMigration{
+		{MigrationStep{1, text.Text.Messages.MigrateConfigDefault}, dropLegacyDefault},
+	}
+)
+
+// migrateCurrentConfig moves the single current_config selection of early
+// versions into the per-namespace [current] table
+func migrateCurrentConfig(raw map[string]interface{}) {
+	legacy, _ := raw["current_config"].(string)
+	delete(raw, "current_config")
+
+	current, _ := raw["current"].(map[string]interface{})
+	if legacy == "" || len(current) > 0 {
+		return
+	}
+	ns, cfg := ParseConfigName(legacy)
+	raw["current"] = map[string]interface{}{ns: cfg}
+}
+
+// dropLegacyDefault removes the top-level default key of early versions, which
+// is no longer read: the selection lives in state.toml
+func dropLegacyDefault(doc *tomledit.Document) {
+	doc.DeleteValue("", "default")
+}
+
+// VersionKey is the root key holding the format version of config.toml and state.toml
+const VersionKey = "version"
+
+// fileVersion returns the format version of decoded TOML data, 0 if it has none.
+// A [version] table is a configuration (or namespace), not a version.
+func fileVersion(raw map[string]interface{}, supported int) (int, error) {
+	v, ok := raw[VersionKey]
+	if !ok || isTable(v) {
+		return 0, nil
+	}
+	n, ok := v.(int64)
+	if !ok || n < 0 {
+		return 0, fmt.Errorf(text.Text.Errors.InvalidFileVersion, v)
+	}
+	if n > int64(supported) {
+		return 0, fmt.Errorf(text.Text.Errors.FileVersionTooNew, n, supported)
+	}
+	return int(n), nil
+}
+
+// migrateStateData applies the pending migrations to decoded state.toml data
+func migrateStateData(raw map[string]interface{}) ([]MigrationStep, error) {
+	version, err := fileVersion(raw, StateVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []MigrationStep
+	for _, m := range stateMigrations {
+		if m.Version > version {
+			m.apply(raw)
+			steps = append(steps, m.MigrationStep)
+		}
+	}
+	return steps, nil
+}
+
+// MigrateState upgrades state.toml data to StateVersion. Returns the data as
+// it would be written and the migrations applied, none if it is up to date.
+func MigrateState(data []byte) ([]byte, []MigrationStep, error) {
+	state, steps, err := decodeState(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := state.encode()
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, steps, nil
+}
+
+// MigrateConfigDocument upgrades a config.toml document to ConfigVersion in
+// place. Returns the migrations applied, none if it is up to date.
+func MigrateConfigDocument(doc *tomledit.Document) ([]MigrationStep, error) {
+	var raw map[string]interface{}
+	if _, err := toml.Decode(string(doc.Bytes()), &raw); err != nil {
+		return nil, err
+	}
+	version, err := fileVersion(raw, ConfigVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	before := string(doc.Bytes())
+	var steps []MigrationStep
+	for _, m := range configMigrations {
+		if m.Version > version {
+			m.apply(doc)
+			steps = append(steps, m.MigrationStep)
+		}
+	}
+	if len(steps) == 0 {
+		return nil, nil
+	}
+
+	// The root key would clash with a [version] table; such files are
+	// migrated again on every load, which the migrations allow, and only
+	// report the migrations that changed something
+	if isTable(raw[VersionKey]) {
+		if string(doc.Bytes()) == before {
+			return nil, nil
+		}
+		return steps, nil
+	}
+	doc.SetRawValue("", VersionKey, strconv.Itoa(ConfigVersion))
+	return steps, nil
+}
+
+// isTable reports whether a decoded TOML value is a table
+func isTable(v interface{}) bool {
+	_, ok := v.(map[string]interface{})
+	return ok
+}
+
+// MigrateConfig upgrades config.toml data to ConfigVersion, keeping comments
+// and layout. Returns the migrated data and the migrations applied.
+func MigrateConfig(data []byte) ([]byte, []MigrationStep, error) {
+	doc := tomledit.Parse(data)
+	steps, err := MigrateConfigDocument(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc.Bytes(), steps, nil
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"flag"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// testMigrationGolden migrates every historic format in testdata/migrate/<kind>
+// and compares the result with its .golden file
+func testMigrationGolden(t *testing.T, kind string, migrate func([]byte) ([]byte, []MigrationStep, error)) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "migrate", kind, "*.toml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, inputs)
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".toml")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(input)
+			require.NoError(t, err)
+
+			got, steps, err := migrate(data)
+			require.NoError(t, err)
+			if strings.HasPrefix(name, "v0") {
+				assert.NotEmpty(t, steps, "an unversioned file should be migrated")
+			} else {
+				assert.Empty(t, steps, "a current file should not be migrated")
+			}
+
+			golden := strings.TrimSuffix(input, ".toml") + ".golden"
+			if *update {
+				require.NoError(t, os.WriteFile(golden, got, 0644))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+
+			// Migrating again changes nothing
+			again, steps, err := migrate(got)
+			require.NoError(t, err)
+			assert.Empty(t, steps)
+			assert.Equal(t, string(got), string(again))
+		})
+	}
+}
+
+func TestMigrateStateGolden(t *testing.T) {
+	testMigrationGolden(t, "state", MigrateState)
+}
+
+func TestMigrateConfigGolden(t *testing.T) {
+	testMigrationGolden(t, "config", MigrateConfig)
+}
+
+func TestMigrateNewerVersion(t *testing.T) {
+	_, _, err := MigrateState([]byte("version = 99\n"))
+	assert.ErrorContains(t, err, "newer than this envpick supports")
+	var corrupt *corruptStateError
+	assert.NotErrorAs(t, err, &corrupt, "a newer state file must not be treated as corrupt")
+
+	_, _, err = MigrateConfig([]byte("version = 99\n"))
+	assert.ErrorContains(t, err, "newer than this envpick supports")
+
+	_, _, err = MigrateConfig([]byte("version = \"1\"\n"))
+	assert.ErrorContains(t, err, "invalid format version")
+}
+
+func TestMigrationRegistriesOrdered(t *testing.T) {
+	for i, m := range stateMigrations {
+		assert.Equal(t, i+1, m.Version, "state migrations should be numbered in order")
+	}
+	assert.Equal(t, StateVersion, len(stateMigrations))
+	for i, m := range configMigrations {
+		assert.Equal(t, i+1, m.Version, "config migrations should be numbered in order")
+	}
+	assert.Equal(t, ConfigVersion, len(configMigrations))
+}
+
+func TestParseConfigsVersionTable(t *testing.T) {
+	// A [version] configuration is not the format version, even in a file
+	// that still needs migrating
+	data := []byte("default = \"dev\"\n\n[dev]\nA = \"1\"\n\n[version]\nAPI_KEY = \"v\"\n")
+	cfg, err := parseConfigs(data)
+	require.NoError(t, err, "a [version] table should load")
+	assert.Equal(t, map[string]map[string]string{"dev": {"A": "1"}, "version": {"API_KEY": "v"}}, cfg.Configs)
+
+	migrated, steps, err := MigrateConfig(data)
+	require.NoError(t, err)
+	assert.NotEmpty(t, steps)
+	var raw map[string]interface{}
+	_, err = toml.Decode(string(migrated), &raw)
+	require.NoError(t, err, "migration must not add a clashing version key")
+
+	cfg, err = parseConfigs([]byte("[version.prod]\nDB_URL = \"p\"\n"))
+	require.NoError(t, err, "a [version.*] namespace should load")
+	assert.Equal(t, []string{"version.prod"}, slices.Sorted(maps.Keys(cfg.Configs)))
+}
+
+func TestLoadConfigLegacyDefault(t *testing.T) {
+	cfg, err := parseConfigs([]byte("default = \"dev\"\n\n[dev]\nA = \"1\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{"dev": {"A": "1"}}, cfg.Configs, "the legacy key should be dropped")
+
+	// Version 0 files never listed [default] or [ns.default] tables
+	data, err := os.ReadFile(filepath.Join("testdata", "migrate", "config", "v0.toml"))
+	require.NoError(t, err)
+	data = append(data, "\n[db.default]\nDB_HOST = \"localhost\"\n\n[db.prod]\nDB_HOST = \"prod\"\n"...)
+	cfg, err = parseConfigs(data)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db.prod", "dev"}, slices.Sorted(maps.Keys(cfg.Configs)), "default tables should stay hidden")
+}
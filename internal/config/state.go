@@ -2,24 +2,64 @@ package config
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/BurntSushi/toml"
 
+	"envpick/internal/fsutil"
 	"envpick/internal/text"
 )
 
 // State represents the state file
 type State struct {
+	// Format version, see StateVersion
+	Version int `toml:"version"`
+
 	// Map of namespace -> current config name (short form, without namespace prefix)
 	Current map[string]string `toml:"current"`
 
-	// Legacy field for backward compatibility (deprecated)
-	CurrentConfig string `toml:"current_config,omitempty"`
+	// Map of namespace -> lease on the current selection, for time-limited activation
+	Leases map[string]Lease `toml:"leases,omitempty"`
+
+	// Map of namespace -> past selections, oldest first
+	History map[string][]HistoryEntry `toml:"history,omitempty"`
+
+	// Map of session id -> selections of one terminal, which override Current there
+	Sessions map[string]Session `toml:"sessions,omitempty"`
+}
+
+// Session holds the selections made in one terminal with 'envpick use --session'
+type Session struct {
+	// PID of the shell that owns the session; the session ends with it
+	PID int `toml:"pid"`
+	// Map of namespace -> config name (short form)
+	Current map[string]string `toml:"current"`
+	// Updated is the time of the last selection, for platforms that cannot
+	// tell whether PID is still running
+	Updated time.Time `toml:"updated"`
+}
+
+// Lease limits how long a selection stays current
+type Lease struct {
+	Expires time.Time `toml:"expires"`
+	// Previous is the selection to restore on expiry (short name, empty for none)
+	Previous string `toml:"previous,omitempty"`
+}
+
+// HistoryEntry records a selection made at a point in time
+type HistoryEntry struct {
+	Config string    `toml:"config"` // short name
+	Time   time.Time `toml:"time"`
 }
 
+// MaxHistory is the number of selections kept per namespace
+const MaxHistory = 100
+
 // GetStatePath returns the path to state.toml
 // This is a variable to allow overriding in tests
 var GetStatePath = func() (string, error) {
@@ -30,63 +70,188 @@ var GetStatePath = func() (string, error) {
 	return filepath.Join(dir, "state.toml"), nil
 }
 
-// LoadState loads the state from state.toml
+// LoadState loads the state from state.toml. A state file that cannot be
+// parsed is moved aside to state.toml.corrupt and an empty state is returned.
 func LoadState() (*State, error) {
-	statePath, err := GetStatePath()
+	state, err := readState()
+	var corrupt *corruptStateError
+	if !errors.As(err, &corrupt) {
+		return state, err
+	}
+
+	// Check again under the lock: another process may have replaced the file
+	err = withStateLock(func() error {
+		state, err = recoverState()
+		return err
+	})
+	return state, err
+}
+
+// UpdateState applies update to the latest state and saves it, holding the
+// state lock throughout so that concurrent read-modify-write cycles from other
+// shells are not lost. Returns the updated state.
+func UpdateState(update func(*State) error) (*State, error) {
+	var state *State
+	err := withStateLock(func() error {
+		var err error
+		if state, err = recoverState(); err != nil {
+			return err
+		}
+		if err := update(state); err != nil {
+			return err
+		}
+		return state.write()
+	})
 	if err != nil {
 		return nil, err
 	}
+	return state, nil
+}
+
+// Save saves the state to state.toml, replacing the file atomically
+func (s *State) Save() error {
+	return withStateLock(s.write)
+}
+
+// corruptStateError reports a state file that exists but cannot be parsed
+type corruptStateError struct {
+	err error
+}
+
+func (e *corruptStateError) Error() string {
+	return fmt.Sprintf(text.Text.Errors.StateFileParse, e.err)
+}
+
+func (e *corruptStateError) Unwrap() error {
+	return e.err
+}
 
-	state := &State{
-		Current: make(map[string]string),
+// readState reads and parses state.toml, returning an empty state if it doesn't exist
+func readState() (*State, error) {
+	statePath, err := GetStatePath()
+	if err != nil {
+		return nil, err
 	}
 
 	data, err := os.ReadFile(statePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return state, nil
+			return &State{Current: make(map[string]string)}, nil
 		}
 		return nil, fmt.Errorf(text.Text.Errors.StateFileRead, err)
 	}
 
-	if _, err := toml.Decode(string(data), state); err != nil {
-		return nil, fmt.Errorf(text.Text.Errors.StateFileParse, err)
+	state, _, err := decodeState(data)
+	return state, err
+}
+
+// decodeState parses state.toml data, upgrading older formats. Returns the
+// migrations applied.
+func decodeState(data []byte) (*State, []MigrationStep, error) {
+	var raw map[string]interface{}
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		return nil, nil, &corruptStateError{err: err}
 	}
 
-	// Migrate from old format if needed
-	if state.CurrentConfig != "" && len(state.Current) == 0 {
-		// Parse the old current_config to determine namespace
-		ns, cfg := ParseConfigName(state.CurrentConfig)
-		state.Current[ns] = cfg
-		state.CurrentConfig = "" // Clear legacy field
+	// A newer format is not corrupt: leave the file alone
+	steps, err := migrateStateData(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf(text.Text.Errors.StateFileParse, err)
 	}
 
-	return state, nil
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(raw); err != nil {
+		return nil, nil, fmt.Errorf(text.Text.Errors.StateEncode, err)
+	}
+	state := &State{}
+	if _, err := toml.Decode(buf.String(), state); err != nil {
+		return nil, nil, &corruptStateError{err: err}
+	}
+	if state.Current == nil {
+		state.Current = make(map[string]string)
+	}
+	return state, steps, nil
 }
 
-// Save saves the state to state.toml
-func (s *State) Save() error {
-	// Ensure config directory exists
-	if err := EnsureConfigDir(); err != nil {
-		return err
+// recoverState reads state.toml, moving it aside and starting over if it cannot
+// be parsed. Must be called with the state lock held.
+func recoverState() (*State, error) {
+	state, err := readState()
+	var corrupt *corruptStateError
+	if !errors.As(err, &corrupt) {
+		return state, err
 	}
 
+	statePath, err := GetStatePath()
+	if err != nil {
+		return nil, err
+	}
+	backup := statePath + ".corrupt"
+	if err := os.Rename(statePath, backup); err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.StateRecover, err)
+	}
+	fmt.Fprintf(os.Stderr, text.Text.Messages.StateRecovered, corrupt, backup)
+
+	return &State{Current: make(map[string]string)}, nil
+}
+
+// write encodes the state and atomically replaces state.toml.
+// Must be called with the state lock held.
+func (s *State) write() error {
 	statePath, err := GetStatePath()
 	if err != nil {
 		return err
 	}
 
+	data, err := s.encode()
+	if err != nil {
+		return err
+	}
+
+	if err := fsutil.WriteFileAtomic(statePath, data, 0644); err != nil {
+		return fmt.Errorf(text.Text.Errors.StateFileWrite, err)
+	}
+
+	return nil
+}
+
+// encode returns the state as written to state.toml, in the current format
+func (s *State) encode() ([]byte, error) {
+	s.Version = StateVersion
+
 	var buf bytes.Buffer
 	encoder := toml.NewEncoder(&buf)
 	if err := encoder.Encode(s); err != nil {
-		return fmt.Errorf(text.Text.Errors.StateEncode, err)
+		return nil, fmt.Errorf(text.Text.Errors.StateEncode, err)
 	}
+	return buf.Bytes(), nil
+}
 
-	if err := os.WriteFile(statePath, buf.Bytes(), 0644); err != nil {
-		return fmt.Errorf(text.Text.Errors.StateFileWrite, err)
+// withStateLock runs fn holding an exclusive advisory lock on state.toml.lock.
+// The lock is on a separate file because state.toml itself is replaced on write.
+func withStateLock(fn func() error) error {
+	// Ensure config directory exists
+	if err := EnsureConfigDir(); err != nil {
+		return err
 	}
 
-	return nil
+	statePath, err := GetStatePath()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(statePath+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf(text.Text.Errors.StateLock, err)
+	}
+	defer f.Close()
+
+	if err := fsutil.LockFile(f); err != nil {
+		return fmt.Errorf(text.Text.Errors.StateLock, err)
+	}
+	defer fsutil.UnlockFile(f)
+
+	return fn()
 }
 
 // GetCurrentConfig returns the current config for the given namespace.
@@ -98,12 +263,237 @@ func (s *State) GetCurrentConfig(namespace string) string {
 	return s.Current[namespace]
 }
 
-// SetCurrentConfig sets the current config for the given namespace.
+// SetCurrentConfig sets the current config for the given namespace, ending any lease.
 func (s *State) SetCurrentConfig(namespace, config string) {
 	if s.Current == nil {
 		s.Current = make(map[string]string)
 	}
 	s.Current[namespace] = config
+	delete(s.Leases, namespace)
+}
+
+// ClearCurrentConfig removes the selection of the given namespace and its lease.
+func (s *State) ClearCurrentConfig(namespace string) {
+	delete(s.Current, namespace)
+	delete(s.Leases, namespace)
+}
+
+// RenameConfig updates the selection and lease that point at the renamed configuration.
+// Moving a configuration to another namespace clears the old selection.
+func (s *State) RenameConfig(oldName, newName string) {
+	oldNs, oldCfg := ParseConfigName(oldName)
+	newNs, newCfg := ParseConfigName(newName)
+
+	if lease, ok := s.Leases[oldNs]; ok && lease.Previous == oldCfg {
+		lease.Previous = ""
+		if newNs == oldNs {
+			lease.Previous = newCfg
+		}
+		s.Leases[oldNs] = lease
+	}
+
+	// History follows the configuration within its namespace
+	s.rewriteHistory(oldNs, func(cfg string) string {
+		if cfg != oldCfg {
+			return cfg
+		}
+		if newNs == oldNs {
+			return newCfg
+		}
+		return ""
+	})
+
+	for _, session := range s.Sessions {
+		if session.Current[oldNs] != oldCfg {
+			continue
+		}
+		if newNs == oldNs {
+			session.Current[oldNs] = newCfg
+		} else {
+			delete(session.Current, oldNs)
+		}
+	}
+
+	if s.GetCurrentConfig(oldNs) != oldCfg {
+		return
+	}
+	if newNs == oldNs {
+		s.Current[oldNs] = newCfg
+		return
+	}
+	delete(s.Current, oldNs)
+	delete(s.Leases, oldNs)
+}
+
+// RemoveConfig clears the selection and lease that point at the removed configuration.
+func (s *State) RemoveConfig(name string) {
+	ns, cfg := ParseConfigName(name)
+	if lease, ok := s.Leases[ns]; ok && lease.Previous == cfg {
+		lease.Previous = ""
+		s.Leases[ns] = lease
+	}
+	s.rewriteHistory(ns, func(c string) string {
+		if c == cfg {
+			return ""
+		}
+		return c
+	})
+	for _, session := range s.Sessions {
+		if session.Current[ns] == cfg {
+			delete(session.Current, ns)
+		}
+	}
+	if s.GetCurrentConfig(ns) == cfg {
+		delete(s.Current, ns)
+		delete(s.Leases, ns)
+	}
+}
+
+// GetSessionConfig returns the selection of namespace in the given session,
+// or empty if the session has none.
+func (s *State) GetSessionConfig(session, namespace string) string {
+	return s.Sessions[session].Current[namespace]
+}
+
+// SetSessionConfig sets the selection of namespace in a session owned by the
+// shell with the given pid.
+func (s *State) SetSessionConfig(session string, pid int, namespace, config string, now time.Time) {
+	if s.Sessions == nil {
+		s.Sessions = make(map[string]Session)
+	}
+	sess := s.Sessions[session]
+	if sess.Current == nil {
+		sess.Current = make(map[string]string)
+	}
+	sess.PID = pid
+	sess.Updated = now
+	sess.Current[namespace] = config
+	s.Sessions[session] = sess
+}
+
+// ClearSessionConfig removes the selection of namespace from a session, and
+// the session once it has no selections left.
+func (s *State) ClearSessionConfig(session, namespace string) {
+	sess, ok := s.Sessions[session]
+	if !ok {
+		return
+	}
+	delete(sess.Current, namespace)
+	if len(sess.Current) == 0 {
+		delete(s.Sessions, session)
+	}
+}
+
+// RemoveDeadSessions removes the sessions that have ended according to alive,
+// and returns their ids sorted.
+func (s *State) RemoveDeadSessions(alive func(Session) bool) []string {
+	var removed []string
+	for id, sess := range s.Sessions {
+		if !alive(sess) {
+			delete(s.Sessions, id)
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// rewriteHistory replaces the configuration of each history entry of namespace
+// with rename(config), dropping the entry if it returns empty.
+func (s *State) rewriteHistory(namespace string, rename func(config string) string) {
+	var history []HistoryEntry
+	for _, entry := range s.History[namespace] {
+		if entry.Config = rename(entry.Config); entry.Config != "" {
+			history = append(history, entry)
+		}
+	}
+	if len(history) == 0 {
+		delete(s.History, namespace)
+		return
+	}
+	s.History[namespace] = history
+}
+
+// RecordSelection appends a selection of namespace to its history, keeping the
+// last MaxHistory entries.
+func (s *State) RecordSelection(namespace, config string, at time.Time) {
+	if s.History == nil {
+		s.History = make(map[string][]HistoryEntry)
+	}
+	history := append(s.History[namespace], HistoryEntry{Config: config, Time: at})
+	if len(history) > MaxHistory {
+		history = history[len(history)-MaxHistory:]
+	}
+	s.History[namespace] = history
+}
+
+// GetHistory returns the past selections of namespace, oldest first.
+func (s *State) GetHistory(namespace string) []HistoryEntry {
+	return s.History[namespace]
+}
+
+// PreviousConfig returns the most recent selection of namespace that differs
+// from current and satisfies exists, or empty if there is none.
+func (s *State) PreviousConfig(namespace, current string, exists func(config string) bool) string {
+	history := s.History[namespace]
+	for i := len(history) - 1; i >= 0; i-- {
+		if cfg := history[i].Config; cfg != current && exists(cfg) {
+			return cfg
+		}
+	}
+	return ""
+}
+
+// SetLease limits the current selection of namespace until expires, after which
+// previous (short name, or empty for none) is selected again.
+func (s *State) SetLease(namespace, previous string, expires time.Time) {
+	if s.Leases == nil {
+		s.Leases = make(map[string]Lease)
+	}
+	s.Leases[namespace] = Lease{Expires: expires, Previous: previous}
+}
+
+// GetLease returns the lease on the current selection of namespace, if any.
+func (s *State) GetLease(namespace string) (Lease, bool) {
+	lease, ok := s.Leases[namespace]
+	return lease, ok
+}
+
+// ExpiredLease describes a selection reverted because its lease expired
+type ExpiredLease struct {
+	Namespace string
+	Config    string // short name of the expired selection
+	Restored  string // short name of the restored selection, empty for none
+}
+
+// HasExpiredLease reports whether any lease has expired at now.
+func (s *State) HasExpiredLease(now time.Time) bool {
+	for _, lease := range s.Leases {
+		if !now.Before(lease.Expires) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpireLeases reverts every selection whose lease has expired at now,
+// sorted by namespace.
+func (s *State) ExpireLeases(now time.Time) []ExpiredLease {
+	var expired []ExpiredLease
+	for ns, lease := range s.Leases {
+		if now.Before(lease.Expires) {
+			continue
+		}
+		expired = append(expired, ExpiredLease{Namespace: ns, Config: s.Current[ns], Restored: lease.Previous})
+		delete(s.Leases, ns)
+		if lease.Previous != "" {
+			s.Current[ns] = lease.Previous
+		} else {
+			delete(s.Current, ns)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].Namespace < expired[j].Namespace })
+	return expired
 }
 
 // CreateDefaultState creates a default state.toml if it doesn't exist
@@ -113,17 +503,19 @@ func CreateDefaultState(defaultConfig string) error {
 		return err
 	}
 
-	if _, err := os.Stat(statePath); err == nil {
-		return nil // File already exists
-	}
+	return withStateLock(func() error {
+		if _, err := os.Stat(statePath); err == nil {
+			return nil // File already exists
+		}
 
-	state := &State{
-		Current: make(map[string]string),
-	}
+		state := &State{
+			Current: make(map[string]string),
+		}
 
-	// Parse the default config to determine namespace
-	ns, cfg := ParseConfigName(defaultConfig)
-	state.SetCurrentConfig(ns, cfg)
+		// Parse the default config to determine namespace
+		ns, cfg := ParseConfigName(defaultConfig)
+		state.SetCurrentConfig(ns, cfg)
 
-	return state.Save()
+		return state.write()
+	})
 }
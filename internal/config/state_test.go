@@ -1,9 +1,14 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -50,8 +55,10 @@ func TestStateMigration(t *testing.T) {
 
 	assert.Equal(t, "prod", state.GetCurrentConfig(""), "after migration, default namespace should be prod")
 
-	// Verify legacy field is cleared
-	assert.Empty(t, state.CurrentConfig, "after migration, CurrentConfig should be empty")
+	// Verify the legacy field is not written back
+	data, err := state.encode()
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "current_config", "after migration, current_config should be gone")
 }
 
 func TestStateMigrationWithNamespace(t *testing.T) {
@@ -110,3 +117,311 @@ func TestStateSaveLoad(t *testing.T) {
 	assert.Equal(t, "staging", loadedState.GetCurrentConfig("db"), "db namespace should be staging")
 	assert.Equal(t, "gcp", loadedState.GetCurrentConfig("deploy"), "deploy namespace should be gcp")
 }
+
+func TestStateRenameConfig(t *testing.T) {
+	state := &State{Current: map[string]string{"": "dev", "db": "local"}}
+
+	state.RenameConfig("dev", "development")
+	assert.Equal(t, "development", state.GetCurrentConfig(""), "selection should follow a rename")
+
+	state.RenameConfig("db.other", "db.renamed")
+	assert.Equal(t, "local", state.GetCurrentConfig("db"), "unselected rename should not change the selection")
+
+	state.RenameConfig("db.local", "cache.local")
+	assert.NotContains(t, state.Current, "db", "moving to another namespace should clear the old selection")
+	assert.NotContains(t, state.Current, "cache", "moving should not select in the new namespace")
+}
+
+func TestStateRemoveConfig(t *testing.T) {
+	state := &State{Current: map[string]string{"": "dev", "db": "local"}}
+
+	state.RemoveConfig("db.prod")
+	assert.Equal(t, "local", state.GetCurrentConfig("db"), "removing another config should keep the selection")
+
+	state.RemoveConfig("db.local")
+	assert.NotContains(t, state.Current, "db", "removing the selected config should clear the selection")
+	assert.Equal(t, "dev", state.GetCurrentConfig(""))
+}
+
+func TestStateLeases(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	state := &State{Current: map[string]string{"": "dev", "db": "local"}}
+
+	state.SetCurrentConfig("", "prod")
+	state.SetLease("", "dev", now.Add(30*time.Minute))
+	state.SetCurrentConfig("db", "prod")
+	state.SetLease("db", "", now.Add(10*time.Minute))
+
+	lease, ok := state.GetLease("")
+	require.True(t, ok)
+	assert.Equal(t, "dev", lease.Previous)
+
+	assert.Empty(t, state.ExpireLeases(now.Add(5*time.Minute)), "no lease has expired yet")
+
+	expired := state.ExpireLeases(now.Add(10 * time.Minute))
+	assert.Equal(t, []ExpiredLease{{Namespace: "db", Config: "prod"}}, expired)
+	assert.NotContains(t, state.Current, "db", "a lease without a previous selection reverts to none")
+
+	expired = state.ExpireLeases(now.Add(time.Hour))
+	assert.Equal(t, []ExpiredLease{{Namespace: "", Config: "prod", Restored: "dev"}}, expired)
+	assert.Equal(t, "dev", state.GetCurrentConfig(""))
+	assert.Empty(t, state.Leases)
+}
+
+func TestStateSetCurrentConfigEndsLease(t *testing.T) {
+	state := &State{Current: map[string]string{}}
+	state.SetCurrentConfig("", "prod")
+	state.SetLease("", "dev", time.Now().Add(time.Hour))
+
+	state.SetCurrentConfig("", "staging")
+	_, ok := state.GetLease("")
+	assert.False(t, ok, "selecting again should end the lease")
+}
+
+func TestStateLeaseRoundTrip(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.toml")
+	originalGetStatePath := GetStatePath
+	GetStatePath = func() (string, error) { return statePath, nil }
+	defer func() { GetStatePath = originalGetStatePath }()
+
+	expires := time.Date(2026, 10, 19, 12, 30, 0, 0, time.UTC)
+	state := &State{Current: map[string]string{"": "prod"}}
+	state.SetLease("", "dev", expires)
+	require.NoError(t, state.Save())
+
+	loaded, err := LoadState()
+	require.NoError(t, err)
+	lease, ok := loaded.GetLease("")
+	require.True(t, ok)
+	assert.True(t, expires.Equal(lease.Expires))
+	assert.Equal(t, "dev", lease.Previous)
+}
+
+func TestStateHistory(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	state := &State{Current: map[string]string{}}
+	exists := func(string) bool { return true }
+
+	assert.Empty(t, state.PreviousConfig("", "", exists), "no history means no previous selection")
+
+	for i, cfg := range []string{"dev", "prod", "staging"} {
+		state.SetCurrentConfig("", cfg)
+		state.RecordSelection("", cfg, now.Add(time.Duration(i)*time.Minute))
+	}
+	assert.Equal(t, "prod", state.PreviousConfig("", "staging", exists))
+	assert.Equal(t, "dev", state.PreviousConfig("", "staging", func(cfg string) bool { return cfg != "prod" }),
+		"configurations that no longer exist are skipped")
+
+	state.RenameConfig("prod", "production")
+	state.RemoveConfig("dev")
+	var names []string
+	for _, entry := range state.GetHistory("") {
+		names = append(names, entry.Config)
+	}
+	assert.Equal(t, []string{"production", "staging"}, names)
+
+	for i := range MaxHistory + 10 {
+		state.RecordSelection("db", "local", now.Add(time.Duration(i)*time.Second))
+	}
+	assert.Len(t, state.GetHistory("db"), MaxHistory, "history should be capped")
+	assert.True(t, now.Add(109*time.Second).Equal(state.GetHistory("db")[MaxHistory-1].Time))
+}
+
+func TestStateHistoryRoundTrip(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.toml")
+	originalGetStatePath := GetStatePath
+	GetStatePath = func() (string, error) { return statePath, nil }
+	defer func() { GetStatePath = originalGetStatePath }()
+
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	state := &State{Current: map[string]string{"db": "local"}}
+	state.RecordSelection("db", "local", at)
+	require.NoError(t, state.Save())
+
+	loaded, err := LoadState()
+	require.NoError(t, err)
+	require.Len(t, loaded.GetHistory("db"), 1)
+	assert.Equal(t, "local", loaded.GetHistory("db")[0].Config)
+	assert.True(t, at.Equal(loaded.GetHistory("db")[0].Time))
+}
+
+func TestStateRecoversFromCorruptFile(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.toml")
+	originalGetStatePath := GetStatePath
+	GetStatePath = func() (string, error) { return statePath, nil }
+	defer func() { GetStatePath = originalGetStatePath }()
+
+	garbage := []byte("[current\n\"\" = \"de")
+	require.NoError(t, os.WriteFile(statePath, garbage, 0644))
+
+	state, err := LoadState()
+	require.NoError(t, err, "a corrupt state file should not be fatal")
+	assert.Empty(t, state.Current)
+
+	backup, err := os.ReadFile(statePath + ".corrupt")
+	require.NoError(t, err, "the corrupt file should be kept aside")
+	assert.Equal(t, garbage, backup)
+	assert.NoFileExists(t, statePath)
+
+	// Updates recover the same way
+	require.NoError(t, os.WriteFile(statePath, garbage, 0644))
+	state, err = UpdateState(func(s *State) error {
+		s.SetCurrentConfig("", "dev")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "dev", state.GetCurrentConfig(""))
+
+	loaded, err := LoadState()
+	require.NoError(t, err)
+	assert.Equal(t, "dev", loaded.GetCurrentConfig(""))
+}
+
+func TestUpdateStateError(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.toml")
+	originalGetStatePath := GetStatePath
+	GetStatePath = func() (string, error) { return statePath, nil }
+	defer func() { GetStatePath = originalGetStatePath }()
+
+	_, err := UpdateState(func(s *State) error {
+		s.SetCurrentConfig("", "dev")
+		return fmt.Errorf("boom")
+	})
+	assert.EqualError(t, err, "boom")
+	assert.NoFileExists(t, statePath, "a failed update should not be saved")
+}
+
+// Environment of TestStateHelperProcess, which makes the test binary act as
+// another envpick process switching configurations
+const (
+	stateHelperPath      = "ENVPICK_TEST_STATE_PATH"
+	stateHelperNamespace = "ENVPICK_TEST_STATE_NAMESPACE"
+)
+
+// stateSwitches is the number of switches made by each worker of the stress test
+const stateSwitches = 25
+
+// switchRepeatedly makes stateSwitches switches in namespace, each recorded in the history
+func switchRepeatedly(namespace string) error {
+	for i := range stateSwitches {
+		cfg := "cfg" + strconv.Itoa(i)
+		_, err := UpdateState(func(s *State) error {
+			s.SetCurrentConfig(namespace, cfg)
+			s.RecordSelection(namespace, cfg, time.Now())
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestStateHelperProcess(t *testing.T) {
+	statePath := os.Getenv(stateHelperPath)
+	if statePath == "" {
+		return
+	}
+	GetStatePath = func() (string, error) { return statePath, nil }
+	if err := switchRepeatedly(os.Getenv(stateHelperNamespace)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestStateConcurrentUpdates(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.toml")
+	originalGetStatePath := GetStatePath
+	GetStatePath = func() (string, error) { return statePath, nil }
+	defer func() { GetStatePath = originalGetStatePath }()
+
+	const goroutines, processes = 8, 4
+	var namespaces []string
+
+	// Other processes, as with several shells switching at once
+	var cmds []*exec.Cmd
+	for i := range processes {
+		ns := "proc" + strconv.Itoa(i)
+		namespaces = append(namespaces, ns)
+		cmd := exec.Command(os.Args[0], "-test.run=^TestStateHelperProcess$")
+		cmd.Env = append(os.Environ(), stateHelperPath+"="+statePath, stateHelperNamespace+"="+ns)
+		cmd.Stderr = os.Stderr
+		require.NoError(t, cmd.Start())
+		cmds = append(cmds, cmd)
+	}
+
+	// Goroutines of this process
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines+1)
+	for i := range goroutines {
+		ns := "goroutine" + strconv.Itoa(i)
+		namespaces = append(namespaces, ns)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- switchRepeatedly(ns)
+		}()
+	}
+
+	// Readers never see a partially written file
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				errs <- nil
+				return
+			default:
+			}
+			if _, err := readState(); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	for _, cmd := range cmds {
+		assert.NoError(t, cmd.Wait(), "helper process should succeed")
+	}
+	close(done)
+	for range goroutines + 1 {
+		assert.NoError(t, <-errs)
+	}
+
+	state, err := LoadState()
+	require.NoError(t, err)
+	for _, ns := range namespaces {
+		assert.Equal(t, "cfg"+strconv.Itoa(stateSwitches-1), state.GetCurrentConfig(ns), "last switch in %s should be kept", ns)
+		assert.Len(t, state.GetHistory(ns), stateSwitches, "no switch in %s should be lost", ns)
+	}
+}
+
+func TestStateSessions(t *testing.T) {
+	now := time.Now()
+	state := &State{Current: map[string]string{"": "dev", "db": "local"}}
+
+	state.SetSessionConfig("100-a", 100, "", "prod", now)
+	state.SetSessionConfig("100-a", 100, "db", "prod", now)
+	state.SetSessionConfig("200-b", 200, "", "staging", now)
+	assert.Equal(t, "prod", state.GetSessionConfig("100-a", ""))
+	assert.Equal(t, now, state.Sessions["100-a"].Updated)
+	assert.Equal(t, "staging", state.GetSessionConfig("200-b", ""))
+	assert.Empty(t, state.GetSessionConfig("200-b", "db"), "sessions only hold their own selections")
+	assert.Empty(t, state.GetSessionConfig("300-c", ""), "unknown sessions have no selections")
+	assert.Equal(t, "dev", state.GetCurrentConfig(""), "sessions should not change the global selection")
+
+	state.RenameConfig("prod", "production")
+	assert.Equal(t, "production", state.GetSessionConfig("100-a", ""), "session selections follow renames")
+	state.RemoveConfig("db.prod")
+	assert.Empty(t, state.GetSessionConfig("100-a", "db"), "removed configs leave sessions")
+
+	state.ClearSessionConfig("200-b", "")
+	assert.NotContains(t, state.Sessions, "200-b", "empty sessions are removed")
+
+	state.SetSessionConfig("200-b", 200, "", "staging", now)
+	removed := state.RemoveDeadSessions(func(sess Session) bool { return sess.PID == 100 })
+	assert.Equal(t, []string{"200-b"}, removed)
+	assert.Contains(t, state.Sessions, "100-a")
+}
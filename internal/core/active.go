@@ -0,0 +1,74 @@
+package core
+
+import (
+	"fmt"
+
+	"envpick/internal/config"
+	"envpick/internal/export"
+	"envpick/internal/fsutil"
+	"envpick/internal/text"
+)
+
+// GetActiveVars returns the resolved variables of the persisted selection of
+// every namespace; terminal sessions don't apply. Later namespaces win when
+// several set the same variable, as with 'envpick env --all-namespaces'.
+// Selections of configurations that no longer exist are skipped.
+func (e *Engine) GetActiveVars() (map[string]string, error) {
+	var names []string
+	for _, name := range e.currentConfigsFull(false) {
+		if _, ok := e.config.Configs[name]; ok {
+			names = append(names, name)
+		}
+	}
+
+	resolved, err := e.config.GetResolvedVars(names...)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string)
+	for _, name := range names {
+		for k, v := range resolved[name] {
+			vars[k] = v
+		}
+	}
+	return vars, nil
+}
+
+// SyncActiveEnv writes the active variables to active.env (dotenv) and to the
+// environment.d file, atomically and readable only by the user. Returns the
+// paths written.
+func (e *Engine) SyncActiveEnv() ([]string, error) {
+	vars, err := e.GetActiveVars()
+	if err != nil {
+		return nil, err
+	}
+	profile := export.Profile{Name: "active", Vars: vars}
+
+	targets := []struct {
+		path   func() (string, error)
+		render export.Formatter
+	}{
+		{config.GetActiveEnvPath, export.Dotenv},
+		// environment.d uses the same quoting rules as systemd EnvironmentFile=
+		{config.GetEnvironmentDPath, export.Systemd},
+	}
+
+	var written []string
+	for _, t := range targets {
+		path, err := t.path()
+		if err != nil {
+			return written, err
+		}
+		data, err := t.render(profile)
+		if err != nil {
+			return written, err
+		}
+		data = append([]byte(text.Text.Formats.ActiveEnvHeader), data...)
+		if err := fsutil.WriteFileAtomic(path, data, 0600); err != nil {
+			return written, fmt.Errorf(text.Text.Errors.ActiveEnvWrite, path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"envpick/internal/config"
+)
+
+func TestEngineSyncActiveEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	activePath := filepath.Join(tmpDir, "active.env")
+	environmentDPath := filepath.Join(tmpDir, "environment.d", "60-envpick.conf")
+
+	originalActive, originalEnvironmentD := config.GetActiveEnvPath, config.GetEnvironmentDPath
+	config.GetActiveEnvPath = func() (string, error) { return activePath, nil }
+	config.GetEnvironmentDPath = func() (string, error) { return environmentDPath, nil }
+	defer func() {
+		config.GetActiveEnvPath, config.GetEnvironmentDPath = originalActive, originalEnvironmentD
+	}()
+
+	cfg := &config.Config{
+		Configs: map[string]map[string]string{
+			"work":     {"API_URL": "https://api.company.com", "PROMPT": "$ ", "_web_url": "https://company.com"},
+			"db.local": {"DB_HOST": "localhost"},
+		},
+	}
+	state := &config.State{
+		Current: map[string]string{"": "work", "db": "local", "gone": "old"},
+	}
+	engine := &Engine{config: cfg, state: state}
+
+	written, err := engine.SyncActiveEnv()
+	require.NoError(t, err)
+	assert.Equal(t, []string{activePath, environmentDPath}, written)
+
+	data, err := os.ReadFile(activePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "API_URL=https://api.company.com\nDB_HOST=localhost\nPROMPT='$ '\n")
+	assert.NotContains(t, string(data), "_web_url", "metadata should not be written")
+
+	data, err = os.ReadFile(environmentDPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "PROMPT=\"\\$ \"\n", "environment.d expands $, so it must be escaped")
+
+	for _, path := range written {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "%s should only be readable by the user", path)
+	}
+}
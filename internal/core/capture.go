@@ -0,0 +1,70 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"envpick/internal/text"
+)
+
+// noisyVars are set by the shell itself and never belong in a configuration
+var noisyVars = map[string]bool{
+	"_":       true,
+	"PWD":     true,
+	"OLDPWD":  true,
+	"SHLVL":   true,
+	"COLUMNS": true,
+	"LINES":   true,
+}
+
+// capturableName matches variable names that can be written to config.toml
+var capturableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// capturable reports whether the variable k may be captured: not shell
+// bookkeeping, not one of envpick's own ENVPICK_ variables, and a valid name
+// that is not metadata (_ prefix)
+func capturable(k string) bool {
+	return !noisyVars[k] && !strings.HasPrefix(k, "ENVPICK_") && !strings.HasPrefix(k, "_") && capturableName.MatchString(k)
+}
+
+// CaptureEnv selects the variables of env that start with one of prefixes or
+// are listed in keys. Prefix matches skip variables that are not capturable;
+// listed keys that are not capturable are an error. Returns the selected
+// variables and the listed keys missing from env.
+func CaptureEnv(env map[string]string, prefixes, keys []string) (map[string]string, []string, error) {
+	var rejected []string
+	for _, k := range keys {
+		if !capturable(k) {
+			rejected = append(rejected, k)
+		}
+	}
+	if len(rejected) > 0 {
+		return nil, nil, fmt.Errorf(text.Text.Errors.CaptureInvalidKeys, strings.Join(rejected, ", "))
+	}
+
+	captured := make(map[string]string)
+	for k, v := range env {
+		if !capturable(k) {
+			continue
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(k, prefix) {
+				captured[k] = v
+				break
+			}
+		}
+	}
+
+	var missing []string
+	for _, k := range keys {
+		if v, ok := env[k]; ok {
+			captured[k] = v
+		} else {
+			missing = append(missing, k)
+		}
+	}
+	sort.Strings(missing)
+	return captured, missing, nil
+}
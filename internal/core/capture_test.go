@@ -0,0 +1,42 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureEnv(t *testing.T) {
+	env := map[string]string{
+		"ANTHROPIC_BASE_URL": "https://api.company.com",
+		"ANTHROPIC_API_KEY":  "sk-work",
+		"API_TIMEOUT":        "30",
+		"APIARY":             "bees",
+		"PWD":                "/home/me",
+		"SHLVL":              "2",
+		"_":                  "/usr/bin/env",
+		"ENVPICK_SESSION":    "abc",
+		"HOME":               "/home/me",
+	}
+
+	captured, missing, err := CaptureEnv(env, []string{"ANTHROPIC_", "API_", "P", "S", "_", "E"}, []string{"HOME", "MISSING"})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"ANTHROPIC_BASE_URL": "https://api.company.com",
+		"ANTHROPIC_API_KEY":  "sk-work",
+		"API_TIMEOUT":        "30",
+		"HOME":               "/home/me",
+	}, captured, "prefixes should skip noisy variables")
+	assert.Equal(t, []string{"MISSING"}, missing)
+}
+
+func TestCaptureEnvRejectsListedKeys(t *testing.T) {
+	env := map[string]string{"HOME": "/home/me", "PWD": "/home/me", "ENVPICK_SESSION": "abc", "_web_url": "x"}
+
+	_, _, err := CaptureEnv(env, nil, []string{"HOME", "PWD", "ENVPICK_SESSION", "_", "_web_url", "BAD-NAME"})
+	require.Error(t, err, "listed keys go through the same filters as prefixes")
+	assert.Contains(t, err.Error(), "PWD, ENVPICK_SESSION, _, _web_url, BAD-NAME")
+	assert.NotContains(t, err.Error(), "HOME")
+}
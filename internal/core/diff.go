@@ -0,0 +1,57 @@
+package core
+
+import "sort"
+
+// Kinds of change reported by DiffVars
+const (
+	ChangeAdded     = "added"
+	ChangeRemoved   = "removed"
+	ChangeChanged   = "changed"
+	ChangeUnchanged = "unchanged"
+)
+
+// Change is the difference of a single key between two sets of variables
+type Change struct {
+	Key  string `json:"key"`
+	Kind string `json:"change"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// DiffVars compares two sets of variables, returning one change per key
+// (including unchanged keys) sorted by key
+func DiffVars(from, to map[string]string) []Change {
+	keys := make(map[string]bool)
+	for k := range from {
+		keys[k] = true
+	}
+	for k := range to {
+		keys[k] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	changes := make([]Change, 0, len(sorted))
+	for _, k := range sorted {
+		oldValue, hadOld := from[k]
+		newValue, hasNew := to[k]
+
+		change := Change{Key: k, Old: oldValue, New: newValue}
+		switch {
+		case !hadOld:
+			change.Kind = ChangeAdded
+		case !hasNew:
+			change.Kind = ChangeRemoved
+		case oldValue != newValue:
+			change.Kind = ChangeChanged
+		default:
+			change.Kind = ChangeUnchanged
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
@@ -0,0 +1,32 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffVars(t *testing.T) {
+	from := map[string]string{
+		"ANTHROPIC_BASE_URL": "https://api.anthropic.com",
+		"ANTHROPIC_API_KEY":  "sk-personal",
+		"API_TIMEOUT_MS":     "300000",
+	}
+	to := map[string]string{
+		"ANTHROPIC_BASE_URL":   "https://api.company.com",
+		"ANTHROPIC_AUTH_TOKEN": "sk-work",
+		"API_TIMEOUT_MS":       "300000",
+	}
+
+	assert.Equal(t, []Change{
+		{Key: "ANTHROPIC_API_KEY", Kind: ChangeRemoved, Old: "sk-personal"},
+		{Key: "ANTHROPIC_AUTH_TOKEN", Kind: ChangeAdded, New: "sk-work"},
+		{Key: "ANTHROPIC_BASE_URL", Kind: ChangeChanged, Old: "https://api.anthropic.com", New: "https://api.company.com"},
+		{Key: "API_TIMEOUT_MS", Kind: ChangeUnchanged, Old: "300000", New: "300000"},
+	}, DiffVars(from, to))
+}
+
+func TestDiffVarsEmpty(t *testing.T) {
+	assert.Empty(t, DiffVars(nil, nil))
+	assert.Equal(t, []Change{{Key: "A", Kind: ChangeAdded, New: "1"}}, DiffVars(nil, map[string]string{"A": "1"}))
+}
@@ -1,7 +1,13 @@
 package core
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"envpick/internal/config"
 	"envpick/internal/selector"
@@ -13,8 +19,13 @@ type Engine struct {
 	config    *config.Config
 	state     *config.State
 	namespace string // current namespace for this engine instance
+	session   string // terminal session of the calling shell (SessionVar), empty outside one
 }
 
+// SessionVar holds the id of the terminal session, set by 'envpick init'. The
+// id starts with the PID of the shell that owns it: "<pid>-<random>"
+const SessionVar = "ENVPICK_SESSION"
+
 // NewEngine creates a new Engine with default namespace, loading config and state
 func NewEngine() (*Engine, error) {
 	return NewEngineWithNamespace("")
@@ -36,6 +47,7 @@ func NewEngineWithNamespace(namespace string) (*Engine, error) {
 		config:    cfg,
 		state:     state,
 		namespace: namespace,
+		session:   os.Getenv(SessionVar),
 	}
 
 	return engine, nil
@@ -50,25 +62,109 @@ func (e *Engine) GetNamespace() string {
 	return e.namespace
 }
 
-// saveState saves the current state
-func (e *Engine) saveState() error {
-	return e.state.Save()
+// updateState applies update to the latest persisted state under the state
+// lock, so that switches made meanwhile by other shells are kept, and uses the
+// result from then on. Sessions of shells that have exited are dropped.
+func (e *Engine) updateState(update func(*config.State) error) error {
+	state, err := config.UpdateState(func(s *config.State) error {
+		s.RemoveDeadSessions(sessionAlive)
+		return update(s)
+	})
+	if err != nil {
+		return err
+	}
+	e.state = state
+	return nil
+}
+
+// currentConfig returns the selection of namespace: this session's if it has
+// one, otherwise the persisted one (short form)
+func (e *Engine) currentConfig(namespace string) string {
+	if name := e.state.GetSessionConfig(e.session, namespace); name != "" {
+		return name
+	}
+	return e.state.GetCurrentConfig(namespace)
+}
+
+// InSession reports whether the selection of namespace comes from this
+// terminal session rather than the persisted one
+func (e *Engine) InSession(namespace string) bool {
+	return e.state.GetSessionConfig(e.session, namespace) != ""
+}
+
+// SessionPinned reports whether this terminal session has a selection of its
+// own in any namespace
+func (e *Engine) SessionPinned() bool {
+	return len(e.state.Sessions[e.session].Current) > 0
 }
 
 // GetCurrentConfig returns the current active configuration name (short form)
 func (e *Engine) GetCurrentConfig() string {
-	return e.state.GetCurrentConfig(e.namespace)
+	return e.currentConfig(e.namespace)
 }
 
 // GetCurrentConfigFull returns the full configuration name (with namespace prefix if applicable)
 func (e *Engine) GetCurrentConfigFull() string {
-	shortName := e.state.GetCurrentConfig(e.namespace)
+	shortName := e.currentConfig(e.namespace)
 	if shortName == "" {
 		return ""
 	}
 	return config.BuildConfigName(e.namespace, shortName)
 }
 
+// GetConfigFull returns the full name of the named configuration in this namespace,
+// or of the current configuration when name is empty
+func (e *Engine) GetConfigFull(name string) (string, error) {
+	if name == "" {
+		fullName := e.GetCurrentConfigFull()
+		if fullName == "" {
+			return "", errors.New(text.Text.Errors.NoCurrentConfig)
+		}
+		name = e.GetCurrentConfig()
+	}
+
+	fullName := config.BuildConfigName(e.namespace, name)
+	if _, ok := e.config.Configs[fullName]; !ok {
+		return "", fmt.Errorf(text.Text.Errors.ConfigNotFound, name)
+	}
+	return fullName, nil
+}
+
+// GetAllCurrentConfigsFull returns the full names of the current configuration in
+// every namespace, ordered by namespace with the default namespace first
+func (e *Engine) GetAllCurrentConfigsFull() []string {
+	return e.currentConfigsFull(true)
+}
+
+// currentConfigsFull returns the full names of the selection of every
+// namespace, with this session's selections overriding the persisted ones if
+// withSession is set
+func (e *Engine) currentConfigsFull(withSession bool) []string {
+	current := make(map[string]string, len(e.state.Current))
+	for ns, name := range e.state.Current {
+		current[ns] = name
+	}
+	if withSession {
+		for ns, name := range e.state.Sessions[e.session].Current {
+			current[ns] = name
+		}
+	}
+
+	namespaces := make([]string, 0, len(current))
+	for ns, name := range current {
+		if name != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+
+	names := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		names = append(names, config.BuildConfigName(ns, current[ns]))
+	}
+	return names
+}
+
 // SetCurrentConfig sets the current configuration (accepts short form name)
 func (e *Engine) SetCurrentConfig(name string) error {
 	// Build full config name for validation
@@ -76,19 +172,217 @@ func (e *Engine) SetCurrentConfig(name string) error {
 	if _, ok := e.config.Configs[fullName]; !ok {
 		return fmt.Errorf(text.Text.Errors.ConfigNotFound, name)
 	}
-	e.state.SetCurrentConfig(e.namespace, name)
-	return e.saveState()
+	// Switching persistently in a session also ends its own selection there
+	return e.updateState(func(s *config.State) error {
+		s.SetCurrentConfig(e.namespace, name)
+		s.ClearSessionConfig(e.session, e.namespace)
+		s.RecordSelection(e.namespace, name, time.Now())
+		return nil
+	})
+}
+
+// SetSessionConfig sets the configuration (short form name) of this terminal
+// session only, overriding the persisted selection until the shell exits
+func (e *Engine) SetSessionConfig(name string) error {
+	fullName := config.BuildConfigName(e.namespace, name)
+	if _, ok := e.config.Configs[fullName]; !ok {
+		return fmt.Errorf(text.Text.Errors.ConfigNotFound, name)
+	}
+	pid, err := SessionPID(e.session)
+	if err != nil {
+		return err
+	}
+
+	return e.updateState(func(s *config.State) error {
+		s.SetSessionConfig(e.session, pid, e.namespace, name, time.Now())
+		s.RecordSelection(e.namespace, name, time.Now())
+		return nil
+	})
+}
+
+// SessionPID returns the PID of the shell that owns a session id
+func SessionPID(session string) (int, error) {
+	if session == "" {
+		return 0, errors.New(text.Text.Errors.NoSession)
+	}
+	prefix, _, _ := strings.Cut(session, "-")
+	pid, err := strconv.Atoi(prefix)
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf(text.Text.Errors.InvalidSession, SessionVar, session)
+	}
+	return pid, nil
 }
 
-// GetOptions returns options for fzf selection (filtered by namespace)
+// SetCurrentConfigFor sets the current configuration (short form name) for
+// duration d, after which the selection from before the lease is restored
+func (e *Engine) SetCurrentConfigFor(name string, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf(text.Text.Errors.InvalidLeaseDuration, d)
+	}
+	fullName := config.BuildConfigName(e.namespace, name)
+	if _, ok := e.config.Configs[fullName]; !ok {
+		return fmt.Errorf(text.Text.Errors.ConfigNotFound, name)
+	}
+
+	return e.updateState(func(s *config.State) error {
+		// Replacing a lease keeps the selection from before the first one
+		previous := s.GetCurrentConfig(e.namespace)
+		if lease, ok := s.GetLease(e.namespace); ok {
+			previous = lease.Previous
+		}
+
+		s.SetCurrentConfig(e.namespace, name)
+		s.ClearSessionConfig(e.session, e.namespace)
+		s.SetLease(e.namespace, previous, time.Now().Add(d))
+		s.RecordSelection(e.namespace, name, time.Now())
+		return nil
+	})
+}
+
+// ClearCurrentConfig removes the persisted selection of this namespace, or of
+// every namespace if all is set, and returns the full names that were selected
+func (e *Engine) ClearCurrentConfig(all bool) ([]string, error) {
+	if len(e.GetAllCurrentConfigsFull()) == 0 || (!all && e.GetCurrentConfig() == "") {
+		return nil, nil
+	}
+
+	// This session's selections are cleared too, so that the shell doesn't
+	// keep exporting them
+	var cleared []string
+	err := e.updateState(func(s *config.State) error {
+		namespaces := []string{e.namespace}
+		if all {
+			namespaces = namespaces[:0]
+			for ns := range s.Current {
+				namespaces = append(namespaces, ns)
+			}
+			for ns := range s.Sessions[e.session].Current {
+				if _, ok := s.Current[ns]; !ok {
+					namespaces = append(namespaces, ns)
+				}
+			}
+			sort.Strings(namespaces)
+		}
+
+		cleared = nil
+		for _, ns := range namespaces {
+			name := s.GetSessionConfig(e.session, ns)
+			if name == "" {
+				name = s.GetCurrentConfig(ns)
+			}
+			if name != "" {
+				cleared = append(cleared, config.BuildConfigName(ns, name))
+			}
+			s.ClearCurrentConfig(ns)
+			s.ClearSessionConfig(e.session, ns)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cleared, nil
+}
+
+// GetLease returns the lease on the current selection of namespace, if any
+func (e *Engine) GetLease(namespace string) (config.Lease, bool) {
+	return e.state.GetLease(namespace)
+}
+
+// ExpireLeases reverts selections whose lease has expired and saves the state
+func (e *Engine) ExpireLeases() ([]config.ExpiredLease, error) {
+	// Most calls find nothing to do; don't take the lock for them
+	now := time.Now()
+	if !e.state.HasExpiredLease(now) {
+		return nil, nil
+	}
+
+	var expired []config.ExpiredLease
+	err := e.updateState(func(s *config.State) error {
+		expired = s.ExpireLeases(now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// PreviousConfig returns the most recent earlier selection in this namespace
+// that still exists (short form name)
+func (e *Engine) PreviousConfig() (string, error) {
+	previous := e.state.PreviousConfig(e.namespace, e.GetCurrentConfig(), func(name string) bool {
+		_, ok := e.config.Configs[config.BuildConfigName(e.namespace, name)]
+		return ok
+	})
+	if previous == "" {
+		return "", errors.New(text.Text.Errors.NoPreviousConfig)
+	}
+	return previous, nil
+}
+
+// GetHistory returns the past selections in this namespace, oldest first
+func (e *Engine) GetHistory() []config.HistoryEntry {
+	return e.state.GetHistory(e.namespace)
+}
+
+// RenameConfig updates the persisted selections after a configuration was
+// renamed (full names)
+func (e *Engine) RenameConfig(oldName, newName string) error {
+	return e.updateState(func(s *config.State) error {
+		s.RenameConfig(oldName, newName)
+		return nil
+	})
+}
+
+// RemoveConfig clears the persisted selection of a removed configuration (full name)
+func (e *Engine) RemoveConfig(name string) error {
+	return e.updateState(func(s *config.State) error {
+		s.RemoveConfig(name)
+		return nil
+	})
+}
+
+// GetOptions returns options for fzf selection (filtered by namespace): the
+// current configuration, then the previous one, then the rest by frecency and name
 func (e *Engine) GetOptions() []selector.Option {
 	var options []selector.Option
-	current := e.state.GetCurrentConfig(e.namespace)
+	current := e.GetCurrentConfig()
 
 	// Get configs for this namespace
 	namespaceConfigs := e.config.GetNamespaceConfigs(e.namespace)
 
+	names := make([]string, 0, len(namespaceConfigs))
 	for name := range namespaceConfigs {
+		names = append(names, name)
+	}
+
+	previous := e.state.PreviousConfig(e.namespace, current, func(name string) bool {
+		_, ok := namespaceConfigs[name]
+		return ok
+	})
+	scores := frecency(e.state.GetHistory(e.namespace), time.Now())
+	rank := func(name string) int {
+		switch name {
+		case current:
+			return 0
+		case previous:
+			return 1
+		}
+		return 2
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := names[i], names[j]
+		if rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		return a < b
+	})
+
+	for _, name := range names {
 		opt := selector.Option{
 			Name: name,
 		}
@@ -97,9 +391,46 @@ func (e *Engine) GetOptions() []selector.Option {
 		if name == current {
 			opt.Status = "active"
 		}
+		if entry, err := e.config.GetEntry(config.BuildConfigName(e.namespace, name)); err == nil {
+			opt.Protected = entry.Protected
+		}
 
 		options = append(options, opt)
 	}
 
 	return options
 }
+
+// ConfigInfo describes a configuration for listing
+type ConfigInfo struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Config    string `json:"config"`
+	Active    bool   `json:"active"`
+}
+
+// ListConfigs returns the configurations in this namespace, or in every namespace
+// if all is set, sorted by namespace and then by name
+func (e *Engine) ListConfigs(all bool) []ConfigInfo {
+	var infos []ConfigInfo
+	for fullName := range e.config.Configs {
+		ns, name := config.ParseConfigName(fullName)
+		if !all && ns != e.namespace {
+			continue
+		}
+		infos = append(infos, ConfigInfo{
+			Name:      fullName,
+			Namespace: ns,
+			Config:    name,
+			Active:    e.currentConfig(ns) == name,
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Namespace != infos[j].Namespace {
+			return infos[i].Namespace < infos[j].Namespace
+		}
+		return infos[i].Config < infos[j].Config
+	})
+	return infos
+}
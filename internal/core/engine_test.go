@@ -1,12 +1,17 @@
 package core
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"envpick/internal/config"
+	"envpick/internal/text"
 )
 
 func TestEngineNamespaceFiltering(t *testing.T) {
@@ -143,3 +148,227 @@ func TestEngineGetNamespace(t *testing.T) {
 
 	assert.Equal(t, "db", engine.GetNamespace(), "namespace should be db")
 }
+
+func TestEngineGetOptionsSorted(t *testing.T) {
+	cfg := &config.Config{
+		Configs: map[string]map[string]string{
+			"staging": {"API_KEY": "staging-key"},
+			"dev":     {"API_KEY": "dev-key"},
+			"prod":    {"API_KEY": "prod-key"},
+			"local":   {"API_KEY": "local-key"},
+		},
+	}
+
+	engine := &Engine{
+		config: cfg,
+		state:  &config.State{Current: map[string]string{}},
+	}
+
+	// Run several times: map iteration order must not leak into the result
+	for range 5 {
+		var names []string
+		for _, opt := range engine.GetOptions() {
+			names = append(names, opt.Name)
+		}
+		assert.Equal(t, []string{"dev", "local", "prod", "staging"}, names, "options should be sorted")
+	}
+}
+
+func TestEngineListConfigs(t *testing.T) {
+	cfg := &config.Config{
+		Configs: map[string]map[string]string{
+			"prod":       {"API_KEY": "prod-key"},
+			"dev":        {"API_KEY": "dev-key"},
+			"db.prod":    {"DB_HOST": "prod.db"},
+			"db.local":   {"DB_HOST": "localhost"},
+			"deploy.aws": {"CLOUD": "aws"},
+		},
+	}
+
+	state := &config.State{
+		Current: map[string]string{
+			"":   "dev",
+			"db": "prod",
+		},
+	}
+
+	engine := &Engine{
+		config:    cfg,
+		state:     state,
+		namespace: "db",
+	}
+
+	// Current namespace only
+	infos := engine.ListConfigs(false)
+	assert.Equal(t, []ConfigInfo{
+		{Name: "db.local", Namespace: "db", Config: "local", Active: false},
+		{Name: "db.prod", Namespace: "db", Config: "prod", Active: true},
+	}, infos)
+
+	// All namespaces, sorted by namespace then name
+	var names []string
+	for _, info := range engine.ListConfigs(true) {
+		names = append(names, info.Name)
+	}
+	assert.Equal(t, []string{"dev", "prod", "db.local", "db.prod", "deploy.aws"}, names)
+}
+
+func TestEngineGetOptionsProtected(t *testing.T) {
+	cfg := &config.Config{
+		Configs: map[string]map[string]string{
+			"dev":  {"API_KEY": "dev-key"},
+			"prod": {"API_KEY": "prod-key", "_protected": "true"},
+		},
+	}
+	engine := &Engine{config: cfg, state: &config.State{Current: map[string]string{}}}
+
+	options := engine.GetOptions()
+	require.Len(t, options, 2)
+	assert.False(t, options[0].Protected, "dev should not be protected")
+	assert.True(t, options[1].Protected, "prod should be protected")
+}
+
+func TestEngineSetCurrentConfigFor(t *testing.T) {
+	cfg := &config.Config{
+		Configs: map[string]map[string]string{
+			"dev":     {"API_KEY": "dev-key"},
+			"prod":    {"API_KEY": "prod-key"},
+			"staging": {"API_KEY": "staging-key"},
+		},
+	}
+	state := &config.State{Current: map[string]string{"": "dev"}}
+
+	statePath := filepath.Join(t.TempDir(), "state.toml")
+	originalGetStatePath := config.GetStatePath
+	config.GetStatePath = func() (string, error) {
+		return statePath, nil
+	}
+	defer func() { config.GetStatePath = originalGetStatePath }()
+	require.NoError(t, state.Save())
+
+	engine := &Engine{config: cfg, state: state}
+
+	require.NoError(t, engine.SetCurrentConfigFor("prod", 30*time.Minute))
+	assert.Equal(t, "prod", engine.GetCurrentConfig())
+	lease, ok := engine.GetLease("")
+	require.True(t, ok, "prod should be leased")
+	assert.Equal(t, "dev", lease.Previous)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), lease.Expires, time.Minute)
+
+	// A second lease still reverts to the selection from before the first one
+	require.NoError(t, engine.SetCurrentConfigFor("staging", time.Minute))
+	lease, _ = engine.GetLease("")
+	assert.Equal(t, "dev", lease.Previous)
+
+	// Let the lease run out
+	runOut, err := config.UpdateState(func(s *config.State) error {
+		s.SetLease("", "dev", time.Now().Add(-time.Second))
+		return nil
+	})
+	require.NoError(t, err)
+	engine.state = runOut
+
+	expired, err := engine.ExpireLeases()
+	require.NoError(t, err)
+	assert.Equal(t, []config.ExpiredLease{{Namespace: "", Config: "staging", Restored: "dev"}}, expired)
+	assert.Equal(t, "dev", engine.GetCurrentConfig())
+
+	assert.Error(t, engine.SetCurrentConfigFor("nonexistent", time.Minute))
+
+	// A lease must have a duration; --for 0 is not a permanent switch
+	for _, d := range []time.Duration{0, -time.Minute} {
+		assert.EqualError(t, engine.SetCurrentConfigFor("prod", d), fmt.Sprintf(text.Text.Errors.InvalidLeaseDuration, d))
+	}
+	assert.Equal(t, "dev", engine.GetCurrentConfig())
+	_, ok = engine.GetLease("")
+	assert.False(t, ok)
+}
+
+func TestEngineClearCurrentConfig(t *testing.T) {
+	cfg := &config.Config{
+		Configs: map[string]map[string]string{
+			"dev":      {"API_KEY": "dev-key"},
+			"db.local": {"DB_HOST": "localhost"},
+			"db.prod":  {"DB_HOST": "prod.db"},
+		},
+	}
+	state := &config.State{Current: map[string]string{"": "dev", "db": "prod"}}
+	state.SetLease("db", "local", time.Now().Add(time.Hour))
+
+	statePath := filepath.Join(t.TempDir(), "state.toml")
+	originalGetStatePath := config.GetStatePath
+	config.GetStatePath = func() (string, error) {
+		return statePath, nil
+	}
+	defer func() { config.GetStatePath = originalGetStatePath }()
+	require.NoError(t, state.Save())
+
+	engine := &Engine{config: cfg, state: state, namespace: "db"}
+
+	cleared, err := engine.ClearCurrentConfig(false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db.prod"}, cleared)
+	assert.Empty(t, engine.GetCurrentConfig())
+	_, ok := engine.GetLease("db")
+	assert.False(t, ok, "clearing a selection should end its lease")
+	assert.Equal(t, []string{"dev"}, engine.GetAllCurrentConfigsFull(), "other namespaces are kept")
+
+	cleared, err = engine.ClearCurrentConfig(false)
+	require.NoError(t, err)
+	assert.Empty(t, cleared, "nothing left to clear in db")
+
+	cleared, err = engine.ClearCurrentConfig(true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev"}, cleared)
+	assert.Empty(t, engine.GetAllCurrentConfigsFull())
+}
+
+func TestEngineSessionConfig(t *testing.T) {
+	cfg := &config.Config{
+		Configs: map[string]map[string]string{
+			"dev":  {"API_KEY": "dev-key"},
+			"prod": {"API_KEY": "prod-key"},
+		},
+	}
+	state := &config.State{Current: map[string]string{"": "dev"}}
+	// A session whose shell has exited is dropped on the next write
+	state.SetSessionConfig("0-dead", 0, "", "prod", time.Now())
+
+	statePath := filepath.Join(t.TempDir(), "state.toml")
+	originalGetStatePath := config.GetStatePath
+	config.GetStatePath = func() (string, error) {
+		return statePath, nil
+	}
+	defer func() { config.GetStatePath = originalGetStatePath }()
+	require.NoError(t, state.Save())
+
+	session := fmt.Sprintf("%d-1234", os.Getpid())
+	engine := &Engine{config: cfg, state: state, session: session}
+
+	require.NoError(t, engine.SetSessionConfig("prod"))
+	assert.Equal(t, "prod", engine.GetCurrentConfig())
+	assert.True(t, engine.InSession(""))
+	assert.NotContains(t, engine.state.Sessions, "0-dead")
+
+	other := &Engine{config: cfg, state: engine.state}
+	assert.Equal(t, "dev", other.GetCurrentConfig(), "other terminals keep the persisted selection")
+	assert.Error(t, other.SetSessionConfig("prod"), "outside a session")
+
+	// A persistent switch from the session ends its own selection
+	require.NoError(t, engine.SetCurrentConfig("dev"))
+	assert.False(t, engine.InSession(""))
+	assert.Empty(t, engine.state.Sessions)
+
+	assert.Error(t, engine.SetSessionConfig("nonexistent"))
+}
+
+func TestSessionPID(t *testing.T) {
+	pid, err := SessionPID("4242-98765")
+	require.NoError(t, err)
+	assert.Equal(t, 4242, pid)
+
+	for _, session := range []string{"", "shell-1", "0-1", "-5"} {
+		_, err := SessionPID(session)
+		assert.Error(t, err, session)
+	}
+}
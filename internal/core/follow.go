@@ -0,0 +1,71 @@
+package core
+
+import (
+	"sort"
+
+	"envpick/internal/config"
+)
+
+// SelectionChange is a namespace whose persisted selection differs from the
+// one a shell applied (full names, empty for none)
+type SelectionChange struct {
+	Namespace string
+	From      string
+	To        string
+}
+
+// PersistedConfigsFull returns the full names of the persisted selection of
+// every namespace, ignoring this session's selections
+func (e *Engine) PersistedConfigsFull() []string {
+	return e.currentConfigsFull(false)
+}
+
+// ChangedSelections compares the selections a shell applied (full names) with
+// the persisted ones, and returns the namespaces that changed, sorted
+func (e *Engine) ChangedSelections(applied []string) []SelectionChange {
+	from := make(map[string]string)
+	for _, name := range applied {
+		ns, _ := config.ParseConfigName(name)
+		from[ns] = name
+	}
+	to := make(map[string]string)
+	for _, name := range e.PersistedConfigsFull() {
+		ns, _ := config.ParseConfigName(name)
+		to[ns] = name
+	}
+
+	namespaces := make(map[string]bool)
+	for ns := range from {
+		namespaces[ns] = true
+	}
+	for ns := range to {
+		namespaces[ns] = true
+	}
+
+	var changes []SelectionChange
+	for ns := range namespaces {
+		if from[ns] != to[ns] {
+			changes = append(changes, SelectionChange{Namespace: ns, From: from[ns], To: to[ns]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Namespace < changes[j].Namespace
+	})
+	return changes
+}
+
+// StaleVars returns the sorted variable names of configuration from that
+// configuration to does not set, i.e. those to unset when switching
+func (e *Engine) StaleVars(from, to string) []string {
+	var keys []string
+	for k := range e.config.Configs[from] {
+		if len(k) == 0 || k[0] == '_' {
+			continue
+		}
+		if _, ok := e.config.Configs[to][k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
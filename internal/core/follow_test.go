@@ -0,0 +1,41 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"envpick/internal/config"
+)
+
+func TestEngineChangedSelections(t *testing.T) {
+	cfg := &config.Config{
+		Configs: map[string]map[string]string{
+			"work":     {"ANTHROPIC_BASE_URL": "https://api.company.com", "ANTHROPIC_API_KEY": "sk-work", "_web_url": "https://w"},
+			"personal": {"ANTHROPIC_BASE_URL": "https://api.anthropic.com"},
+			"db.local": {"DB_HOST": "localhost"},
+			"db.prod":  {"DB_HOST": "prod.db"},
+			"cloud.a":  {"CLOUD": "a"},
+		},
+	}
+	// Switched to personal and db.prod in another terminal, cloud cleared
+	state := &config.State{Current: map[string]string{"": "personal", "db": "prod"}}
+	state.SetSessionConfig("1-1", 1, "", "work", time.Now())
+	engine := &Engine{config: cfg, state: state, session: "1-1"}
+
+	changes := engine.ChangedSelections([]string{"work", "db.local", "cloud.a"})
+	assert.Equal(t, []SelectionChange{
+		{Namespace: "", From: "work", To: "personal"},
+		{Namespace: "cloud", From: "cloud.a", To: ""},
+		{Namespace: "db", From: "db.local", To: "db.prod"},
+	}, changes, "every namespace is followed, by its persisted selection")
+
+	assert.Empty(t, engine.ChangedSelections([]string{"db.prod", "personal"}), "order doesn't matter")
+
+	// Keys the new selection sets are overwritten by its exports instead
+	assert.Equal(t, []string{"ANTHROPIC_API_KEY"}, engine.StaleVars("work", "personal"))
+	assert.Empty(t, engine.StaleVars("db.local", "db.prod"))
+	assert.Equal(t, []string{"CLOUD"}, engine.StaleVars("cloud.a", ""))
+	assert.Empty(t, engine.StaleVars("", "personal"), "nothing to unset without a previous selection")
+}
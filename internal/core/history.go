@@ -0,0 +1,27 @@
+package core
+
+import (
+	"time"
+
+	"envpick/internal/config"
+)
+
+// frecency scores the configurations in history by how often and how recently
+// they were selected: recent selections weigh more than old ones
+func frecency(history []config.HistoryEntry, now time.Time) map[string]float64 {
+	scores := make(map[string]float64)
+	for _, entry := range history {
+		age := now.Sub(entry.Time)
+		switch {
+		case age < time.Hour:
+			scores[entry.Config] += 4
+		case age < 24*time.Hour:
+			scores[entry.Config] += 2
+		case age < 7*24*time.Hour:
+			scores[entry.Config] += 1
+		default:
+			scores[entry.Config] += 0.25
+		}
+	}
+	return scores
+}
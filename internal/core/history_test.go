@@ -0,0 +1,64 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"envpick/internal/config"
+)
+
+func TestFrecency(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	history := []config.HistoryEntry{
+		{Config: "old", Time: now.Add(-30 * 24 * time.Hour)},
+		{Config: "old", Time: now.Add(-20 * 24 * time.Hour)},
+		{Config: "week", Time: now.Add(-3 * 24 * time.Hour)},
+		{Config: "recent", Time: now.Add(-10 * time.Minute)},
+	}
+
+	scores := frecency(history, now)
+	assert.Greater(t, scores["recent"], scores["week"], "recent selections should rank first")
+	assert.Greater(t, scores["week"], scores["old"], "old selections should weigh less")
+	assert.Zero(t, scores["never"])
+}
+
+func TestEngineGetOptionsFrecency(t *testing.T) {
+	now := time.Now()
+	cfg := &config.Config{
+		Configs: map[string]map[string]string{
+			"dev":     {"API_KEY": "dev-key"},
+			"local":   {"API_KEY": "local-key"},
+			"prod":    {"API_KEY": "prod-key"},
+			"staging": {"API_KEY": "staging-key"},
+			"test":    {"API_KEY": "test-key"},
+		},
+	}
+	state := &config.State{
+		Current: map[string]string{"": "staging"},
+		History: map[string][]config.HistoryEntry{"": {
+			{Config: "prod", Time: now.Add(-2 * time.Hour)},
+			{Config: "prod", Time: now.Add(-90 * time.Minute)},
+			{Config: "test", Time: now.Add(-time.Hour - time.Minute)},
+			{Config: "dev", Time: now.Add(-20 * time.Minute)},
+			{Config: "staging", Time: now.Add(-10 * time.Minute)},
+		}},
+	}
+	engine := &Engine{config: cfg, state: state}
+
+	var names []string
+	for _, opt := range engine.GetOptions() {
+		names = append(names, opt.Name)
+	}
+	// current, previous, then by frecency, then never-selected by name
+	assert.Equal(t, []string{"staging", "dev", "prod", "test", "local"}, names)
+
+	previous, err := engine.PreviousConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "dev", previous)
+
+	engine.state.History = nil
+	_, err = engine.PreviousConfig()
+	assert.Error(t, err, "without history there is no previous configuration")
+}
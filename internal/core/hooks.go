@@ -0,0 +1,100 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"envpick/internal/config"
+	"envpick/internal/text"
+)
+
+// Environment variables passed to hooks
+const (
+	HookPrevVar = "ENVPICK_PREV" // configuration being switched away from
+	HookNextVar = "ENVPICK_NEXT" // configuration being switched to
+)
+
+// RunSwitchHooks runs the _on_deactivate hook of prev, then the _on_activate
+// hook of next (full names; either may be empty). Each hook runs with its own
+// configuration's variables applied, and its output goes to out.
+//
+// A failing hook returns an error unless its configuration sets
+// _hook_failure = "warn". If the activate hook fails, prev's activate hook is
+// run again to undo its deactivation.
+func (e *Engine) RunSwitchHooks(prev, next string, out io.Writer) error {
+	if prev == next {
+		return nil
+	}
+
+	if err := e.runHook(prev, false, prev, next, out); err != nil {
+		return err
+	}
+	if err := e.runHook(next, true, prev, next, out); err != nil {
+		if undoErr := e.runHook(prev, true, next, prev, out); undoErr != nil {
+			fmt.Fprintf(out, text.Text.Messages.HookWarning, undoErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// runHook runs the activate or deactivate hook of the named configuration
+func (e *Engine) runHook(name string, activate bool, prev, next string, out io.Writer) error {
+	// Nothing to run for no selection or a selection that no longer exists
+	if _, ok := e.config.Configs[name]; !ok {
+		return nil
+	}
+	entry, err := e.config.GetEntry(name)
+	if err != nil {
+		return err
+	}
+
+	hook, command := "_on_deactivate", entry.OnDeactivate
+	if activate {
+		hook, command = "_on_activate", entry.OnActivate
+	}
+	if command == "" {
+		return nil
+	}
+
+	err = e.execHook(name, command, entry.HookTimeout, prev, next, out)
+	if err == nil {
+		return nil
+	}
+	err = fmt.Errorf(text.Text.Errors.HookFailed, hook, name, err)
+	if entry.HookFailure == config.HookWarn {
+		fmt.Fprintf(out, text.Text.Messages.HookWarning, err)
+		return nil
+	}
+	return err
+}
+
+func (e *Engine) execHook(name, command string, timeout time.Duration, prev, next string, out io.Writer) error {
+	resolved, err := e.config.GetResolvedVars(name)
+	if err != nil {
+		return err
+	}
+	vars := resolved[name]
+	vars[HookPrevVar] = prev
+	vars[HookNextVar] = next
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = ApplyEnviron(os.Environ(), vars)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.WaitDelay = 100 * time.Millisecond
+
+	err = cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf(text.Text.Errors.HookTimeout, timeout)
+	}
+	return err
+}
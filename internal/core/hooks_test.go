@@ -0,0 +1,78 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"envpick/internal/config"
+)
+
+func TestEngineRunSwitchHooks(t *testing.T) {
+	cfg := &config.Config{
+		Configs: map[string]map[string]string{
+			"personal": {
+				"CONTEXT":        "home",
+				"_on_deactivate": `echo "leave $CONTEXT for $ENVPICK_NEXT"`,
+				"_on_activate":   `echo "enter $CONTEXT from $ENVPICK_PREV"`,
+			},
+			"work": {
+				"CONTEXT":      "office",
+				"_on_activate": `echo "enter $CONTEXT from $ENVPICK_PREV"`,
+			},
+			"broken": {
+				"_on_activate": "echo oops; exit 3",
+			},
+			"flaky": {
+				"_on_activate":  "exit 1",
+				"_hook_failure": "warn",
+			},
+			"slow": {
+				"_on_activate":  "sleep 5",
+				"_hook_timeout": "100ms",
+			},
+		},
+	}
+	engine := &Engine{config: cfg, state: &config.State{Current: map[string]string{}}}
+
+	t.Run("deactivate then activate", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, engine.RunSwitchHooks("personal", "work", &out))
+		assert.Equal(t, "leave home for work\nenter office from personal\n", out.String())
+	})
+
+	t.Run("same configuration runs nothing", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, engine.RunSwitchHooks("work", "work", &out))
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("missing previous configuration is skipped", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, engine.RunSwitchHooks("removed", "work", &out))
+		assert.Equal(t, "enter office from removed\n", out.String())
+	})
+
+	t.Run("abort restores the previous configuration", func(t *testing.T) {
+		var out bytes.Buffer
+		err := engine.RunSwitchHooks("personal", "broken", &out)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "broken")
+		assert.Equal(t, "leave home for broken\noops\nenter home from broken\n", out.String())
+	})
+
+	t.Run("warn continues", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, engine.RunSwitchHooks("", "flaky", &out))
+		assert.Contains(t, out.String(), "flaky")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		var out bytes.Buffer
+		err := engine.RunSwitchHooks("", "slow", &out)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "timed out")
+	})
+}
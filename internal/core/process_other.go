@@ -0,0 +1,18 @@
+//go:build !unix && !windows
+
+package core
+
+import (
+	"time"
+
+	"envpick/internal/config"
+)
+
+// sessionTTL is how long a session outlives its last selection where shells
+// cannot be checked for liveness
+const sessionTTL = 24 * time.Hour
+
+// sessionAlive reports whether a session is recent enough to keep
+func sessionAlive(s config.Session) bool {
+	return time.Since(s.Updated) < sessionTTL
+}
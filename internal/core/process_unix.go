@@ -0,0 +1,19 @@
+//go:build unix
+
+package core
+
+import (
+	"errors"
+	"syscall"
+
+	"envpick/internal/config"
+)
+
+// sessionAlive reports whether the shell that owns a session is running
+func sessionAlive(s config.Session) bool {
+	if s.PID <= 0 {
+		return false
+	}
+	err := syscall.Kill(s.PID, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
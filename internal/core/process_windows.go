@@ -0,0 +1,33 @@
+//go:build windows
+
+package core
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+
+	"envpick/internal/config"
+)
+
+// stillActive is the exit code GetExitCodeProcess reports for a running process
+const stillActive = 259 // STILL_ACTIVE
+
+// sessionAlive reports whether the shell that owns a session is running
+func sessionAlive(s config.Session) bool {
+	if s.PID <= 0 {
+		return false
+	}
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(s.PID))
+	if err != nil {
+		// The process exists but belongs to someone else
+		return errors.Is(err, windows.ERROR_ACCESS_DENIED)
+	}
+	defer windows.CloseHandle(h)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(h, &code); err != nil {
+		return true
+	}
+	return code == stillActive
+}
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"envpick/internal/config"
+)
+
+// Status values of a namespace's persisted selection compared to the live environment
+const (
+	StatusApplied = "applied" // every variable matches
+	StatusPartial = "partial" // some variables match
+	StatusDrifted = "drifted" // no variable matches
+	StatusMissing = "missing" // the persisted configuration no longer exists
+)
+
+// NamespaceStatus describes the persisted selection of one namespace
+type NamespaceStatus struct {
+	Namespace string `json:"namespace"`
+	Config    string `json:"config"`
+	Status    string `json:"status"`
+	Matched   int    `json:"matched"`
+	Total     int    `json:"total"`
+	// Differing lists the keys whose live value is missing or different (never the values)
+	Differing []string `json:"differing,omitempty"`
+	// LeaseExpires is when a time-limited selection ends (envpick use --for)
+	LeaseExpires *time.Time `json:"lease_expires,omitempty"`
+	// Session is set when the selection belongs to this terminal session only
+	Session bool `json:"session,omitempty"`
+}
+
+// ParseEnviron converts os.Environ() output into a map
+func ParseEnviron(environ []string) map[string]string {
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	return env
+}
+
+// ApplyEnviron returns environ ("KEY=value" entries) with vars set, replacing
+// existing entries in place and appending new ones in sorted order
+func ApplyEnviron(environ []string, vars map[string]string) []string {
+	result := make([]string, 0, len(environ)+len(vars))
+	seen := make(map[string]bool, len(vars))
+	for _, kv := range environ {
+		k, _, _ := strings.Cut(kv, "=")
+		if v, ok := vars[k]; ok {
+			if !seen[k] {
+				result = append(result, k+"="+v)
+				seen[k] = true
+			}
+			continue
+		}
+		result = append(result, kv)
+	}
+
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		if !seen[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		result = append(result, k+"="+vars[k])
+	}
+	return result
+}
+
+// UnsetEnviron returns environ ("KEY=value" entries) without the given keys
+func UnsetEnviron(environ []string, keys ...string) []string {
+	result := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		k, _, _ := strings.Cut(kv, "=")
+		if !slices.Contains(keys, k) {
+			result = append(result, kv)
+		}
+	}
+	return result
+}
+
+// GetStatus compares the selection of every namespace with env,
+// ordered by namespace with the default namespace first
+func (e *Engine) GetStatus(env map[string]string) ([]NamespaceStatus, error) {
+	var (
+		statuses []NamespaceStatus
+		names    []string
+	)
+
+	for _, fullName := range e.GetAllCurrentConfigsFull() {
+		if _, ok := e.config.Configs[fullName]; ok {
+			names = append(names, fullName)
+		}
+	}
+
+	resolved, err := e.config.GetResolvedVars(names...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fullName := range e.GetAllCurrentConfigsFull() {
+		ns, name := config.ParseConfigName(fullName)
+		status := NamespaceStatus{Namespace: ns, Config: name, Session: e.InSession(ns)}
+		if lease, ok := e.state.GetLease(ns); ok && !status.Session {
+			status.LeaseExpires = &lease.Expires
+		}
+
+		vars, ok := resolved[fullName]
+		if !ok {
+			status.Status = StatusMissing
+			statuses = append(statuses, status)
+			continue
+		}
+
+		status.Total = len(vars)
+		status.Matched, status.Differing = compareVars(vars, env)
+
+		switch {
+		case status.Matched == status.Total:
+			status.Status = StatusApplied
+		case status.Matched > 0:
+			status.Status = StatusPartial
+		default:
+			status.Status = StatusDrifted
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// compareVars counts the variables of vars set to the same value in env and
+// returns the sorted keys that are missing or different
+func compareVars(vars, env map[string]string) (int, []string) {
+	matched := 0
+	var differing []string
+	for k, v := range vars {
+		if live, set := env[k]; set && live == v {
+			matched++
+		} else {
+			differing = append(differing, k)
+		}
+	}
+	sort.Strings(differing)
+	return matched, differing
+}
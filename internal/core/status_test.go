@@ -0,0 +1,73 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"envpick/internal/config"
+)
+
+func TestParseEnviron(t *testing.T) {
+	env := ParseEnviron([]string{"A=1", "B=x=y", "EMPTY=", "INVALID"})
+	assert.Equal(t, map[string]string{"A": "1", "B": "x=y", "EMPTY": ""}, env)
+}
+
+func TestApplyEnviron(t *testing.T) {
+	environ := []string{"HOME=/home/me", "API_URL=http://old", "PATH=/bin", "API_URL=http://dup"}
+	vars := map[string]string{"API_URL": "http://new", "DEBUG": "true", "A": "1"}
+
+	assert.Equal(t, []string{
+		"HOME=/home/me",
+		"API_URL=http://new",
+		"PATH=/bin",
+		"A=1",
+		"DEBUG=true",
+	}, ApplyEnviron(environ, vars))
+}
+
+func TestUnsetEnviron(t *testing.T) {
+	environ := []string{"HOME=/home/me", "ENVPICK_SELECTIONS=dev", "PATH=/bin", "ENVPICK_LEASE_KEYS=A B"}
+
+	assert.Equal(t, []string{"HOME=/home/me", "PATH=/bin"},
+		UnsetEnviron(environ, "ENVPICK_SELECTIONS", "ENVPICK_LEASE_KEYS", "ENVPICK_LEASE_EXPIRES"))
+}
+
+func TestEngineGetStatus(t *testing.T) {
+	cfg := &config.Config{
+		Configs: map[string]map[string]string{
+			"dev":        {"API_URL": "http://localhost", "DEBUG": "true"},
+			"db.local":   {"DB_HOST": "localhost", "DB_PORT": "5432"},
+			"deploy.aws": {"CLOUD": "aws"},
+		},
+	}
+
+	state := &config.State{
+		Current: map[string]string{
+			"":       "dev",
+			"db":     "local",
+			"deploy": "aws",
+			"cache":  "removed",
+		},
+	}
+
+	engine := &Engine{config: cfg, state: state}
+
+	env := map[string]string{
+		"API_URL": "http://localhost",
+		"DEBUG":   "true",
+		"DB_HOST": "localhost",
+		"DB_PORT": "6543",
+		"CLOUD":   "gcp",
+	}
+
+	statuses, err := engine.GetStatus(env)
+	require.NoError(t, err, "GetStatus should succeed")
+	require.Len(t, statuses, 4, "should report every persisted namespace")
+
+	assert.Equal(t, NamespaceStatus{Namespace: "", Config: "dev", Status: StatusApplied, Matched: 2, Total: 2}, statuses[0])
+	assert.Equal(t, NamespaceStatus{Namespace: "cache", Config: "removed", Status: StatusMissing}, statuses[1])
+	assert.Equal(t, NamespaceStatus{Namespace: "db", Config: "local", Status: StatusPartial, Matched: 1, Total: 2, Differing: []string{"DB_PORT"}}, statuses[2])
+	assert.Equal(t, NamespaceStatus{Namespace: "deploy", Config: "aws", Status: StatusDrifted, Matched: 0, Total: 1, Differing: []string{"CLOUD"}}, statuses[3])
+}
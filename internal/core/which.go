@@ -0,0 +1,193 @@
+package core
+
+import (
+	"sort"
+
+	"envpick/internal/config"
+	"envpick/internal/resolve"
+)
+
+// NamespaceMatch is the configuration of a namespace that best matches the live environment
+type NamespaceMatch struct {
+	Namespace string `json:"namespace"`
+	// Config is the best matching configuration, empty if no variable matches
+	Config  string  `json:"config"`
+	Score   float64 `json:"score"`
+	Matched int     `json:"matched"`
+	Total   int     `json:"total"`
+	// Persisted is the configuration selected in state, if any
+	Persisted string `json:"persisted,omitempty"`
+	// Differing lists keys whose live value differs from the persisted configuration
+	Differing []string `json:"differing,omitempty"`
+	// Unresolved lists configurations left out because a reference failed to resolve
+	Unresolved []UnresolvedConfig `json:"unresolved,omitempty"`
+}
+
+// UnresolvedConfig is a configuration that could not be compared with the environment
+type UnresolvedConfig struct {
+	Config string `json:"config"`
+	Error  string `json:"error"`
+}
+
+// candidate is a configuration compared with the environment on its literal
+// values; references are only resolved when it could still be the best match
+type candidate struct {
+	name    string
+	vars    map[string]string
+	matched int      // literal values set to the same value in env
+	pending []string // keys with a reference whose variable is set in env
+}
+
+// bound is the most variables of c that can match once its references are resolved
+func (c candidate) bound() int {
+	return c.matched + len(c.pending)
+}
+
+// DetectActive finds, for every namespace, the configuration whose variables best
+// match env. The score is the fraction of a configuration's variables that match;
+// ties go to the configuration with more matching variables, then to the persisted one.
+//
+// Literal values are compared first. References are only resolved for variables
+// set in env, of configurations that could still beat the best match so far; a
+// configuration whose references fail is reported in Unresolved and skipped.
+func (e *Engine) DetectActive(env map[string]string) []NamespaceMatch {
+	candidates := make(map[string][]candidate)
+	best := make(map[string]*NamespaceMatch)
+	for _, ns := range e.config.GetNamespaces() {
+		best[ns] = &NamespaceMatch{Namespace: ns, Persisted: e.currentConfig(ns)}
+	}
+
+	for _, fullName := range e.config.GetConfigNames() {
+		ns, _ := config.ParseConfigName(fullName)
+		c, err := e.literalCandidate(fullName, env)
+		if err != nil {
+			best[ns].unresolved(fullName, err)
+			continue
+		}
+		candidates[ns] = append(candidates[ns], c)
+	}
+
+	for ns, match := range best {
+		cs := candidates[ns]
+
+		// Most promising first, so that the others can mostly be ruled out
+		// without resolving their references
+		sort.SliceStable(cs, func(i, j int) bool {
+			return score(cs[i].bound(), len(cs[i].vars)) > score(cs[j].bound(), len(cs[j].vars))
+		})
+
+		for _, c := range cs {
+			_, name := config.ParseConfigName(c.name)
+			persisted := name == match.Persisted
+			if !persisted && (c.bound() == 0 || !match.beatenBy(name, score(c.bound(), len(c.vars)), c.bound())) {
+				continue
+			}
+
+			matched, differing, err := e.resolveCandidate(c, env)
+			if err != nil {
+				match.unresolved(c.name, err)
+				continue
+			}
+			if persisted {
+				match.Differing = differing
+			}
+			if matched == 0 || !match.beatenBy(name, score(matched, len(c.vars)), matched) {
+				continue
+			}
+
+			match.Config = name
+			match.Score = score(matched, len(c.vars))
+			match.Matched = matched
+			match.Total = len(c.vars)
+		}
+
+		sort.Slice(match.Unresolved, func(i, j int) bool {
+			return match.Unresolved[i].Config < match.Unresolved[j].Config
+		})
+	}
+
+	matches := make([]NamespaceMatch, 0, len(best))
+	for _, match := range best {
+		matches = append(matches, *match)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Namespace < matches[j].Namespace
+	})
+	return matches
+}
+
+// literalCandidate compares the literal values of a configuration with env
+func (e *Engine) literalCandidate(fullName string, env map[string]string) (candidate, error) {
+	entry, err := e.config.GetEntry(fullName)
+	if err != nil {
+		return candidate{}, err
+	}
+
+	c := candidate{name: fullName, vars: entry.Vars}
+	for k, v := range entry.Vars {
+		live, set := env[k]
+		switch {
+		case !set:
+		case resolve.IsRef(v):
+			c.pending = append(c.pending, k)
+		case live == v:
+			c.matched++
+		}
+	}
+	sort.Strings(c.pending)
+	return c, nil
+}
+
+// resolveCandidate resolves the pending references of a candidate and returns
+// how many of its variables match env and the sorted keys that don't
+func (e *Engine) resolveCandidate(c candidate, env map[string]string) (int, []string, error) {
+	vars := c.vars
+	if len(c.pending) > 0 {
+		resolved, err := e.config.ResolveVars(c.name, c.pending)
+		if err != nil {
+			return 0, nil, err
+		}
+		vars = make(map[string]string, len(c.vars))
+		for k, v := range c.vars {
+			vars[k] = v
+		}
+		for k, v := range resolved {
+			vars[k] = v
+		}
+	}
+	matched, differing := compareVars(vars, env)
+	return matched, differing, nil
+}
+
+// beatenBy reports whether configuration name, with the given score and number
+// of matching variables, is a better match than the current one
+func (m *NamespaceMatch) beatenBy(name string, score float64, matched int) bool {
+	switch {
+	case m.Config == "":
+		return true
+	case score != m.Score:
+		return score > m.Score
+	case matched != m.Matched:
+		return matched > m.Matched
+	case name == m.Persisted:
+		return true
+	case m.Config == m.Persisted:
+		return false
+	default:
+		return name < m.Config
+	}
+}
+
+// unresolved records a configuration that could not be compared
+func (m *NamespaceMatch) unresolved(fullName string, err error) {
+	_, name := config.ParseConfigName(fullName)
+	m.Unresolved = append(m.Unresolved, UnresolvedConfig{Config: name, Error: err.Error()})
+}
+
+// score is the fraction of a configuration's variables that match
+func score(matched, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(matched) / float64(total)
+}
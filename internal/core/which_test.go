@@ -0,0 +1,100 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"envpick/internal/config"
+)
+
+func TestEngineDetectActive(t *testing.T) {
+	cfg := &config.Config{
+		Configs: map[string]map[string]string{
+			"personal": {"ANTHROPIC_BASE_URL": "https://api.anthropic.com", "ANTHROPIC_API_KEY": "sk-personal"},
+			"work":     {"ANTHROPIC_BASE_URL": "https://api.company.com", "ANTHROPIC_AUTH_TOKEN": "sk-work"},
+			"db.local": {"DB_HOST": "localhost", "DB_PORT": "5432"},
+			"db.prod":  {"DB_HOST": "prod.db", "DB_PORT": "5432"},
+			"deploy.a": {"CLOUD": "aws"},
+		},
+	}
+
+	state := &config.State{
+		Current: map[string]string{
+			"":   "personal",
+			"db": "local",
+		},
+	}
+
+	engine := &Engine{config: cfg, state: state}
+
+	// Shell was switched to work and db.prod without updating state
+	env := map[string]string{
+		"ANTHROPIC_BASE_URL":   "https://api.company.com",
+		"ANTHROPIC_AUTH_TOKEN": "sk-work",
+		"DB_HOST":              "prod.db",
+		"DB_PORT":              "5432",
+	}
+
+	matches := engine.DetectActive(env)
+	require.Len(t, matches, 3, "should report every namespace")
+
+	assert.Equal(t, NamespaceMatch{
+		Namespace: "",
+		Config:    "work",
+		Score:     1,
+		Matched:   2,
+		Total:     2,
+		Persisted: "personal",
+		Differing: []string{"ANTHROPIC_API_KEY", "ANTHROPIC_BASE_URL"},
+	}, matches[0])
+
+	assert.Equal(t, "prod", matches[1].Config, "db.prod should beat db.local")
+	assert.InDelta(t, 1.0, matches[1].Score, 0.001)
+	assert.Equal(t, []string{"DB_HOST"}, matches[1].Differing)
+
+	assert.Empty(t, matches[2].Config, "deploy has no matching configuration")
+	assert.Equal(t, "deploy", matches[2].Namespace)
+}
+
+func TestEngineDetectActivePrefersPersistedOnTie(t *testing.T) {
+	cfg := &config.Config{
+		Configs: map[string]map[string]string{
+			"a": {"SHARED": "1"},
+			"b": {"SHARED": "1"},
+		},
+	}
+	engine := &Engine{config: cfg, state: &config.State{Current: map[string]string{"": "b"}}}
+
+	matches := engine.DetectActive(map[string]string{"SHARED": "1"})
+	require.Len(t, matches, 1)
+	assert.Equal(t, "b", matches[0].Config, "persisted configuration should win a tie")
+}
+
+func TestEngineDetectActiveResolvesLazily(t *testing.T) {
+	dir := t.TempDir()
+	ran := filepath.Join(dir, "ran")
+
+	cfg := &config.Config{
+		Configs: map[string]map[string]string{
+			"personal": {"API_URL": "https://a", "TOKEN": "cmd://echo personal-token"},
+			// Cannot beat personal once it is resolved
+			"work": {"API_URL": "https://b", "TOKEN": "cmd://touch " + ran + "; echo work-token"},
+			// References of unset variables never take part
+			"idle": {"IDLE_TOKEN": "cmd://touch " + ran, "UNSET_URL": "env://ENVPICK_TEST_SURELY_UNSET"},
+			// Could tie with personal, but its reference fails
+			"broken": {"API_URL": "https://a", "TOKEN": "cmd://exit 3"},
+		},
+	}
+	engine := &Engine{config: cfg, state: &config.State{Current: map[string]string{}}}
+
+	matches := engine.DetectActive(map[string]string{"API_URL": "https://a", "TOKEN": "personal-token"})
+	require.Len(t, matches, 1)
+	assert.Equal(t, "personal", matches[0].Config)
+	assert.InDelta(t, 1.0, matches[0].Score, 0.001)
+	require.Len(t, matches[0].Unresolved, 1, "a failing reference skips its configuration")
+	assert.Equal(t, "broken", matches[0].Unresolved[0].Config)
+	assert.NoFileExists(t, ran, "references of configurations that cannot match should not be resolved")
+}
@@ -0,0 +1,241 @@
+// Package dotenv parses .env files.
+//
+// Supported syntax:
+//
+//	# comment
+//	KEY=value                 unquoted, trailing "# comment" is dropped
+//	KEY= # comment            an empty value
+//	export KEY=value          the export prefix is ignored
+//	KEY='literal $value'      single (or backtick) quotes: no escapes, may span lines
+//	KEY="line\nnext"          double quotes: \n \r \t \" \\ \$ escapes, may span lines
+//
+// Variable references such as ${OTHER} are kept as written.
+package dotenv
+
+import (
+	"fmt"
+	"strings"
+
+	"envpick/internal/text"
+)
+
+// Entry is a variable assignment read from a dotenv file
+type Entry struct {
+	Key   string
+	Value string
+	Line  int // line the assignment starts on
+}
+
+// Parse reads dotenv data and returns its assignments in file order.
+// When a key is assigned more than once the last value wins, at the position
+// of the first assignment.
+func Parse(data []byte) ([]Entry, error) {
+	p := &parser{src: strings.ReplaceAll(string(data), "\r\n", "\n"), line: 1}
+
+	var entries []Entry
+	index := make(map[string]int)
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return entries, nil
+		}
+		switch p.peek() {
+		case '\n':
+			p.next()
+			continue
+		case '#':
+			p.skipLine()
+			continue
+		}
+
+		line := p.line
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		if i, ok := index[key]; ok {
+			entries[i].Value = value
+			continue
+		}
+		index[key] = len(entries)
+		entries = append(entries, Entry{Key: key, Value: value, Line: line})
+	}
+}
+
+type parser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	return p.src[p.pos]
+}
+
+func (p *parser) next() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+// skipBlank skips spaces and tabs, but not newlines
+func (p *parser) skipBlank() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// skipLine skips to the start of the next line
+func (p *parser) skipLine() {
+	for !p.eof() && p.next() != '\n' {
+	}
+}
+
+// parseKey reads "[export] KEY =" and returns KEY
+func (p *parser) parseKey() (string, error) {
+	key := p.word()
+	if key == "export" && !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipBlank()
+		key = p.word()
+	}
+	if key == "" || !isKeyStart(key[0]) {
+		return "", fmt.Errorf(text.Text.Errors.DotenvInvalidKey, p.line)
+	}
+
+	p.skipBlank()
+	if p.eof() || p.peek() != '=' {
+		return "", fmt.Errorf(text.Text.Errors.DotenvMissingEquals, p.line, key)
+	}
+	p.next()
+	return key, nil
+}
+
+// word reads a run of key characters
+func (p *parser) word() string {
+	start := p.pos
+	for !p.eof() && isKeyChar(p.peek()) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// parseValue reads the value after "=" up to and including the end of its line
+func (p *parser) parseValue() (string, error) {
+	p.skipBlank()
+	if p.eof() {
+		return "", nil
+	}
+
+	var (
+		value string
+		err   error
+	)
+	switch p.peek() {
+	case '\'', '`':
+		value, err = p.literal(p.peek())
+	case '"':
+		value, err = p.quoted()
+	default:
+		return p.unquoted(), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// Only a comment may follow a quoted value
+	p.skipBlank()
+	if !p.eof() && p.peek() != '\n' && p.peek() != '#' {
+		return "", fmt.Errorf(text.Text.Errors.DotenvTrailingText, p.line)
+	}
+	p.skipLine()
+	return value, nil
+}
+
+// unquoted reads the rest of the line, dropping a comment: a # at the start
+// of the value or after whitespace
+func (p *parser) unquoted() string {
+	start := p.pos
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+	value := p.src[start:p.pos]
+	p.skipLine()
+
+	for i := 0; i < len(value); i++ {
+		if value[i] == '#' && (i == 0 || value[i-1] == ' ' || value[i-1] == '\t') {
+			value = value[:i]
+			break
+		}
+	}
+	return strings.TrimRight(value, " \t")
+}
+
+// literal reads a value enclosed in quote, without escapes
+func (p *parser) literal(quote byte) (string, error) {
+	line := p.line
+	p.next()
+	start := p.pos
+	for !p.eof() {
+		if p.peek() == quote {
+			value := p.src[start:p.pos]
+			p.next()
+			return value, nil
+		}
+		p.next()
+	}
+	return "", fmt.Errorf(text.Text.Errors.DotenvUnterminated, line)
+}
+
+// quoted reads a double-quoted value, expanding escapes
+func (p *parser) quoted() (string, error) {
+	line := p.line
+	p.next()
+
+	var b strings.Builder
+	for !p.eof() {
+		c := p.next()
+		switch {
+		case c == '"':
+			return b.String(), nil
+		case c == '\\' && !p.eof():
+			esc := p.next()
+			switch esc {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\', '$':
+				b.WriteByte(esc)
+			default:
+				// Unknown escapes are kept as written
+				b.WriteByte('\\')
+				b.WriteByte(esc)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf(text.Text.Errors.DotenvUnterminated, line)
+}
+
+func isKeyStart(c byte) bool {
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+func isKeyChar(c byte) bool {
+	return isKeyStart(c) || (c >= '0' && c <= '9') || c == '.' || c == '-'
+}
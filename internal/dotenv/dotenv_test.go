@@ -0,0 +1,81 @@
+package dotenv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	data := `# Database
+DB_HOST=localhost
+DB_PORT = 5432   # default port
+export API_URL=https://example.com/#anchor
+EMPTY=
+QUOTED_EMPTY=""
+SINGLE='no $escapes \n here'
+BACKTICK=` + "`it's`" + `
+DOUBLE="tab\there \"quoted\" \$HOME \x"
+MULTI="first
+second"
+LITERAL_MULTI='-----BEGIN KEY-----
+abc
+-----END KEY-----' # pem
+REF=${DB_HOST}:${DB_PORT}
+DB_HOST=db.internal
+COMMENTED= # not a value
+HASH_START=#also a comment
+`
+
+	entries, err := Parse([]byte(data))
+	require.NoError(t, err)
+
+	expected := []Entry{
+		{Key: "DB_HOST", Value: "db.internal", Line: 2},
+		{Key: "DB_PORT", Value: "5432", Line: 3},
+		{Key: "API_URL", Value: "https://example.com/#anchor", Line: 4},
+		{Key: "EMPTY", Value: "", Line: 5},
+		{Key: "QUOTED_EMPTY", Value: "", Line: 6},
+		{Key: "SINGLE", Value: `no $escapes \n here`, Line: 7},
+		{Key: "BACKTICK", Value: "it's", Line: 8},
+		{Key: "DOUBLE", Value: "tab\there \"quoted\" $HOME \\x", Line: 9},
+		{Key: "MULTI", Value: "first\nsecond", Line: 10},
+		{Key: "LITERAL_MULTI", Value: "-----BEGIN KEY-----\nabc\n-----END KEY-----", Line: 12},
+		{Key: "REF", Value: "${DB_HOST}:${DB_PORT}", Line: 15},
+		{Key: "COMMENTED", Value: "", Line: 17},
+		{Key: "HASH_START", Value: "", Line: 18},
+	}
+	assert.Equal(t, expected, entries)
+}
+
+func TestParseCRLFAndExportKey(t *testing.T) {
+	entries, err := Parse([]byte("export=1\r\nexport  NAME=\"a\\nb\"\r\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Key: "export", Value: "1", Line: 1},
+		{Key: "NAME", Value: "a\nb", Line: 2},
+	}, entries)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  string
+	}{
+		{name: "missing equals", input: "A=1\nJUSTAWORD\n", line: "line 2"},
+		{name: "invalid key", input: "1ABC=x", line: "line 1"},
+		{name: "unterminated double", input: "A=1\nB=\"open\nstill open\n", line: "line 2"},
+		{name: "unterminated single", input: "B='open", line: "line 1"},
+		{name: "text after quote", input: "A=\"x\" y", line: "line 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.line)
+		})
+	}
+}
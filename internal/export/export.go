@@ -0,0 +1,220 @@
+// Package export renders configurations in formats understood by other tools.
+package export
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"envpick/internal/text"
+)
+
+// Profile is a configuration to export
+type Profile struct {
+	Name    string            // full configuration name
+	Vars    map[string]string // resolved variables
+	Secrets map[string]bool   // variables holding secrets
+}
+
+// Formatter renders a profile
+type Formatter func(p Profile) ([]byte, error)
+
+// Formats maps format names to their formatter
+var Formats = map[string]Formatter{
+	"dotenv":  Dotenv,
+	"json":    JSON,
+	"yaml":    YAML,
+	"docker":  Docker,
+	"systemd": Systemd,
+	"k8s":     Kubernetes,
+	"github":  GitHub,
+}
+
+// FormatNames returns the names of all formats, sorted
+func FormatNames() []string {
+	names := make([]string, 0, len(Formats))
+	for name := range Formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render renders p in the named format
+func Render(format string, p Profile) ([]byte, error) {
+	f, ok := Formats[format]
+	if !ok {
+		return nil, fmt.Errorf(text.Text.Errors.ExportUnknownFormat, format, strings.Join(FormatNames(), ", "))
+	}
+	return f(p)
+}
+
+// plainValue matches values that need no quoting in dotenv and systemd files
+var plainValue = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]+$`)
+
+// Dotenv renders KEY=value lines, quoted only when needed. Values with newlines
+// or single quotes use double quotes with \n, \", \\ and \$ escapes.
+func Dotenv(p Profile) ([]byte, error) {
+	var b bytes.Buffer
+	for _, k := range sortedKeys(p.Vars) {
+		v := p.Vars[k]
+		switch {
+		case plainValue.MatchString(v):
+			fmt.Fprintf(&b, "%s=%s\n", k, v)
+		case !strings.ContainsAny(v, "'\n\r"):
+			fmt.Fprintf(&b, "%s='%s'\n", k, v)
+		default:
+			r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+			fmt.Fprintf(&b, "%s=\"%s\"\n", k, r.Replace(v))
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// JSON renders a single object with keys sorted
+func JSON(p Profile) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(p.Vars); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// YAML renders a mapping with double-quoted values
+func YAML(p Profile) ([]byte, error) {
+	var b bytes.Buffer
+	for _, k := range sortedKeys(p.Vars) {
+		fmt.Fprintf(&b, "%s: %s\n", yamlKey(k), yamlString(p.Vars[k]))
+	}
+	return b.Bytes(), nil
+}
+
+// Docker renders a file for docker run --env-file, which takes values
+// literally and cannot represent newlines
+func Docker(p Profile) ([]byte, error) {
+	var b bytes.Buffer
+	for _, k := range sortedKeys(p.Vars) {
+		v := p.Vars[k]
+		if strings.ContainsAny(v, "\n\r") {
+			return nil, fmt.Errorf(text.Text.Errors.ExportMultiline, k, "docker")
+		}
+		fmt.Fprintf(&b, "%s=%s\n", k, v)
+	}
+	return b.Bytes(), nil
+}
+
+// Systemd renders a file for EnvironmentFile=. Values that need it are
+// double-quoted, escaping \, ", ` and $; newlines are kept inside the quotes.
+func Systemd(p Profile) ([]byte, error) {
+	var b bytes.Buffer
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", `$`, `\$`)
+	for _, k := range sortedKeys(p.Vars) {
+		v := p.Vars[k]
+		if plainValue.MatchString(v) {
+			fmt.Fprintf(&b, "%s=%s\n", k, v)
+		} else {
+			fmt.Fprintf(&b, "%s=\"%s\"\n", k, r.Replace(v))
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// Kubernetes renders a ConfigMap with the plain variables and a Secret with
+// the secret ones, both named after the profile. Either is omitted when empty.
+func Kubernetes(p Profile) ([]byte, error) {
+	plain := make(map[string]string)
+	secret := make(map[string]string)
+	for k, v := range p.Vars {
+		if p.Secrets[k] {
+			secret[k] = v
+		} else {
+			plain[k] = v
+		}
+	}
+
+	name := kubernetesName(p.Name)
+	var docs []string
+	if len(plain) > 0 {
+		docs = append(docs, kubernetesManifest("ConfigMap", name, "data", "", plain))
+	}
+	if len(secret) > 0 {
+		docs = append(docs, kubernetesManifest("Secret", name, "stringData", "type: Opaque\n", secret))
+	}
+	return []byte(strings.Join(docs, "---\n")), nil
+}
+
+func kubernetesManifest(kind, name, field, extra string, vars map[string]string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "apiVersion: v1\nkind: %s\nmetadata:\n  name: %s\n%s%s:\n", kind, name, extra, field)
+	for _, k := range sortedKeys(vars) {
+		fmt.Fprintf(&b, "  %s: %s\n", yamlKey(k), yamlString(vars[k]))
+	}
+	return b.String()
+}
+
+// invalidKubernetesName matches runs of characters not allowed in resource names
+var invalidKubernetesName = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// kubernetesName turns a configuration name into a DNS-1123 subdomain
+func kubernetesName(name string) string {
+	name = invalidKubernetesName.ReplaceAllString(strings.ToLower(name), "-")
+	return strings.Trim(name, ".-")
+}
+
+// GitHub renders lines to append to $GITHUB_ENV. Multi-line values use the
+// KEY<<DELIMITER syntax with a delimiter derived from a hash of the value, so
+// that it cannot occur in the value and the output is reproducible.
+func GitHub(p Profile) ([]byte, error) {
+	var b bytes.Buffer
+	for _, k := range sortedKeys(p.Vars) {
+		v := p.Vars[k]
+		if !strings.ContainsAny(v, "\n\r") {
+			fmt.Fprintf(&b, "%s=%s\n", k, v)
+			continue
+		}
+		sum := sha256.Sum256([]byte(k + "=" + v))
+		delimiter := "ENVPICK_EOF_" + hex.EncodeToString(sum[:8])
+		fmt.Fprintf(&b, "%s<<%s\n%s\n%s\n", k, delimiter, v, delimiter)
+	}
+	return b.Bytes(), nil
+}
+
+// yamlReserved are plain scalars YAML 1.1 parsers read as booleans or null
+var yamlReserved = map[string]bool{
+	"y": true, "n": true, "yes": true, "no": true, "on": true, "off": true,
+	"true": true, "false": true, "null": true, "~": true,
+}
+
+func yamlKey(k string) string {
+	if yamlReserved[strings.ToLower(k)] {
+		return yamlString(k)
+	}
+	return k
+}
+
+// yamlString quotes s as a YAML double-quoted scalar, whose escapes are a
+// superset of JSON's
+func yamlString(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,80 @@
+package export
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"envpick/internal/dotenv"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// sampleProfile holds values that need escaping in at least one format
+func sampleProfile() Profile {
+	return Profile{
+		Name: "db.Staging_EU",
+		Vars: map[string]string{
+			"API_URL":       "https://api.example.com/v1?x=1&y=2",
+			"DB_PASSWORD":   `p@ss "word" $HOME`,
+			"EMPTY":         "",
+			"GREETING":      "hello world # not a comment",
+			"QUOTE":         "it's",
+			"PRIVATE_TOKEN": "-----BEGIN-----\nabc\\n\n-----END-----",
+			"UNICODE":       "naïve ✓",
+			"YES":           "true",
+		},
+		Secrets: map[string]bool{"DB_PASSWORD": true, "PRIVATE_TOKEN": true},
+	}
+}
+
+func TestFormatsGolden(t *testing.T) {
+	for _, format := range FormatNames() {
+		t.Run(format, func(t *testing.T) {
+			p := sampleProfile()
+			if format == "docker" {
+				delete(p.Vars, "PRIVATE_TOKEN")
+			}
+
+			got, err := Render(format, p)
+			require.NoError(t, err)
+
+			golden := filepath.Join("testdata", format+".golden")
+			if *update {
+				require.NoError(t, os.WriteFile(golden, got, 0644))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func TestDotenvRoundTrip(t *testing.T) {
+	p := sampleProfile()
+	data, err := Dotenv(p)
+	require.NoError(t, err)
+
+	entries, err := dotenv.Parse(data)
+	require.NoError(t, err)
+
+	parsed := make(map[string]string)
+	for _, e := range entries {
+		parsed[e.Key] = e.Value
+	}
+	assert.Equal(t, p.Vars, parsed, "dotenv output should parse back to the same values")
+}
+
+func TestDockerRejectsMultiline(t *testing.T) {
+	_, err := Render("docker", sampleProfile())
+	assert.Error(t, err, "docker env files cannot hold newlines")
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	_, err := Render("xml", sampleProfile())
+	assert.Error(t, err)
+}
@@ -0,0 +1,16 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package fsutil
+
+import "os"
+
+// LockFile is a no-op on platforms without flock; writes are still
+// atomic, but concurrent updates may be lost
+func LockFile(f *os.File) error {
+	return nil
+}
+
+// UnlockFile is a no-op on platforms without flock
+func UnlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,226 @@
+package resolve
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"envpick/internal/fsutil"
+	"envpick/internal/text"
+)
+
+// Cache is an encrypted on-disk cache of resolved values.
+// Each entry carries its own expiry; expired entries are ignored and pruned on write.
+type Cache struct {
+	Path    string // encrypted cache file
+	KeyPath string // 32-byte AES key, created on first write
+
+	// now is a variable to allow overriding in tests
+	now func() time.Time
+}
+
+// cacheEntry is a single cached value
+type cacheEntry struct {
+	Value   string    `json:"value"`
+	Expires time.Time `json:"expires"`
+}
+
+// NewCache returns a cache stored in dir
+func NewCache(dir string) *Cache {
+	return &Cache{
+		Path:    filepath.Join(dir, "cache.enc"),
+		KeyPath: filepath.Join(dir, "cache.key"),
+		now:     time.Now,
+	}
+}
+
+// cacheKey hashes a reference so that commands and paths are not stored in clear text
+func cacheKey(ref string) string {
+	sum := sha256.Sum256([]byte(ref))
+	return hex.EncodeToString(sum[:])
+}
+
+// Lookup returns the unexpired cached values for the given references
+func (c *Cache) Lookup(refs []string) (map[string]string, error) {
+	entries, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	now := c.now()
+	for _, ref := range refs {
+		if entry, ok := entries[cacheKey(ref)]; ok && now.Before(entry.Expires) {
+			values[ref] = entry.Value
+		}
+	}
+	return values, nil
+}
+
+// Store adds values for the given references, each expiring after its TTL
+func (c *Cache) Store(values map[string]string, ttls map[string]time.Duration) error {
+	return c.withLock(func() error {
+		entries, err := c.read()
+		if err != nil {
+			// Start over rather than fail on an unreadable cache
+			entries = make(map[string]cacheEntry)
+		}
+
+		now := c.now()
+		for key, entry := range entries {
+			if !now.Before(entry.Expires) {
+				delete(entries, key)
+			}
+		}
+		for ref, ttl := range ttls {
+			entries[cacheKey(ref)] = cacheEntry{Value: values[ref], Expires: now.Add(ttl)}
+		}
+
+		return c.write(entries)
+	})
+}
+
+// Clear removes the cache file and its key
+func (c *Cache) Clear() error {
+	return c.withLock(func() error {
+		for _, path := range []string{c.Path, c.KeyPath} {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf(text.Text.Errors.CacheClear, err)
+			}
+		}
+		return nil
+	})
+}
+
+// read decrypts the cache file; a missing file is an empty cache
+func (c *Cache) read() (map[string]cacheEntry, error) {
+	entries := make(map[string]cacheEntry)
+
+	data, err := os.ReadFile(c.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, fmt.Errorf(text.Text.Errors.CacheRead, err)
+	}
+
+	key, err := os.ReadFile(c.KeyPath)
+	if err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.CacheRead, err)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.CacheRead, err)
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf(text.Text.Errors.CacheRead, errors.New(text.Text.Errors.CacheCorrupt))
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.CacheRead, err)
+	}
+
+	var stored map[string]cacheEntry
+	if err := json.Unmarshal(plaintext, &stored); err != nil {
+		return nil, fmt.Errorf(text.Text.Errors.CacheRead, err)
+	}
+	for key, entry := range stored {
+		entries[key] = entry
+	}
+	return entries, nil
+}
+
+// write encrypts entries and atomically replaces the cache file
+func (c *Cache) write(entries map[string]cacheEntry) error {
+	key, err := c.loadOrCreateKey()
+	if err != nil {
+		return fmt.Errorf(text.Text.Errors.CacheWrite, err)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return fmt.Errorf(text.Text.Errors.CacheWrite, err)
+	}
+
+	plaintext, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf(text.Text.Errors.CacheWrite, err)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf(text.Text.Errors.CacheWrite, err)
+	}
+	data := aead.Seal(nonce, nonce, plaintext, nil)
+
+	if err := fsutil.WriteFileAtomic(c.Path, data, 0600); err != nil {
+		return fmt.Errorf(text.Text.Errors.CacheWrite, err)
+	}
+	return nil
+}
+
+// withLock runs fn holding an exclusive advisory lock on the cache, so that
+// concurrent shells don't drop each other's entries or race to create the key
+func (c *Cache) withLock(fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+		return fmt.Errorf(text.Text.Errors.CacheLock, err)
+	}
+
+	f, err := os.OpenFile(c.Path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf(text.Text.Errors.CacheLock, err)
+	}
+	defer f.Close()
+
+	if err := fsutil.LockFile(f); err != nil {
+		return fmt.Errorf(text.Text.Errors.CacheLock, err)
+	}
+	defer fsutil.UnlockFile(f)
+
+	return fn()
+}
+
+// loadOrCreateKey returns the cache key, generating it on first use
+func (c *Cache) loadOrCreateKey() ([]byte, error) {
+	key, err := os.ReadFile(c.KeyPath)
+	if err == nil {
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.KeyPath), 0755); err != nil {
+		return nil, err
+	}
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	// A stale cache encrypted with a previous key can never be read again
+	_ = os.Remove(c.Path)
+	if err := fsutil.WriteFileAtomic(c.KeyPath, key, 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// newAEAD returns an AES-GCM cipher for key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,93 @@
+package resolve
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheStoreLookup(t *testing.T) {
+	cache := NewCache(t.TempDir())
+
+	err := cache.Store(
+		map[string]string{"cmd://a": "value-a", "cmd://b": "value-b"},
+		map[string]time.Duration{"cmd://a": time.Hour, "cmd://b": time.Minute},
+	)
+	require.NoError(t, err, "Store should succeed")
+
+	values, err := cache.Lookup([]string{"cmd://a", "cmd://b", "cmd://c"})
+	require.NoError(t, err, "Lookup should succeed")
+	assert.Equal(t, map[string]string{"cmd://a": "value-a", "cmd://b": "value-b"}, values)
+
+	// Cache file must not contain values or references in clear text
+	data, err := os.ReadFile(cache.Path)
+	require.NoError(t, err, "cache file should exist")
+	assert.NotContains(t, string(data), "value-a")
+	assert.NotContains(t, string(data), "cmd://a")
+
+	info, err := os.Stat(cache.KeyPath)
+	require.NoError(t, err, "key file should exist")
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "key file should be private")
+}
+
+func TestCacheExpiry(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	err := cache.Store(
+		map[string]string{"cmd://short": "s", "cmd://long": "l"},
+		map[string]time.Duration{"cmd://short": time.Minute, "cmd://long": 8 * time.Hour},
+	)
+	require.NoError(t, err, "Store should succeed")
+
+	now = now.Add(time.Hour)
+	values, err := cache.Lookup([]string{"cmd://short", "cmd://long"})
+	require.NoError(t, err, "Lookup should succeed")
+	assert.Equal(t, map[string]string{"cmd://long": "l"}, values, "only unexpired entries should be returned")
+}
+
+func TestCacheConcurrentStore(t *testing.T) {
+	cache := NewCache(t.TempDir())
+
+	var refs []string
+	var wg sync.WaitGroup
+	for i := range 8 {
+		ref := fmt.Sprintf("cmd://echo %d", i)
+		refs = append(refs, ref)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := cache.Store(map[string]string{ref: ref}, map[string]time.Duration{ref: time.Hour})
+			assert.NoError(t, err, "Store should succeed")
+		}()
+	}
+	wg.Wait()
+
+	values, err := cache.Lookup(refs)
+	require.NoError(t, err, "Lookup should succeed")
+	assert.Len(t, values, len(refs), "no concurrent Store should drop another's entry")
+}
+
+func TestCacheClear(t *testing.T) {
+	cache := NewCache(t.TempDir())
+
+	err := cache.Store(map[string]string{"cmd://a": "a"}, map[string]time.Duration{"cmd://a": time.Hour})
+	require.NoError(t, err, "Store should succeed")
+
+	require.NoError(t, cache.Clear(), "Clear should succeed")
+	assert.NoFileExists(t, cache.Path)
+	assert.NoFileExists(t, cache.KeyPath)
+
+	// Clearing an empty cache is not an error
+	require.NoError(t, cache.Clear(), "second Clear should succeed")
+
+	values, err := cache.Lookup([]string{"cmd://a"})
+	require.NoError(t, err, "Lookup on empty cache should succeed")
+	assert.Empty(t, values)
+}
@@ -0,0 +1,241 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"envpick/internal/text"
+)
+
+// refSeparator separates a provider scheme from its reference (e.g. "cmd://pass show api")
+const refSeparator = "://"
+
+// Provider fetches the value behind a reference of one scheme
+type Provider struct {
+	// Timeout bounds a single fetch; a slow provider never blocks the others
+	Timeout time.Duration
+	Fetch   func(ctx context.Context, ref string) (string, error)
+}
+
+// Providers maps a reference scheme to its provider.
+// Values using an unknown scheme (e.g. "https://...") are treated as literals.
+var Providers = map[string]Provider{
+	"cmd":  {Timeout: 10 * time.Second, Fetch: fetchCommand},
+	"file": {Timeout: 2 * time.Second, Fetch: fetchFile},
+	"env":  {Timeout: time.Second, Fetch: fetchEnv},
+}
+
+// Job is a single value to resolve
+type Job struct {
+	Value string
+	// TTL enables caching of the resolved value when greater than zero
+	TTL time.Duration
+}
+
+// Resolver resolves provider references, optionally through a Cache
+type Resolver struct {
+	Cache *Cache
+}
+
+// ParseRef splits a value into provider scheme and reference.
+// Returns ok=false for literal values.
+func ParseRef(value string) (scheme, ref string, ok bool) {
+	scheme, ref, found := strings.Cut(value, refSeparator)
+	if !found {
+		return "", "", false
+	}
+	if _, known := Providers[scheme]; !known {
+		return "", "", false
+	}
+	return scheme, ref, true
+}
+
+// IsRef reports whether a value references a provider
+func IsRef(value string) bool {
+	_, _, ok := ParseRef(value)
+	return ok
+}
+
+// Resolve returns the resolved value for each job, in order.
+// Literal values are returned unchanged; distinct references are fetched
+// concurrently, each bounded by its provider's timeout.
+func (r *Resolver) Resolve(jobs []Job) ([]string, error) {
+	results := make([]string, len(jobs))
+
+	// Group jobs by value so that a reference used by several keys is fetched once
+	pending := make(map[string][]int)
+	ttls := make(map[string]time.Duration)
+	for i, job := range jobs {
+		if !IsRef(job.Value) {
+			results[i] = job.Value
+			continue
+		}
+		pending[job.Value] = append(pending[job.Value], i)
+		// Keep the shortest positive TTL when several profiles share a reference
+		if ttl, seen := ttls[job.Value]; !seen || (job.TTL > 0 && (ttl == 0 || job.TTL < ttl)) {
+			ttls[job.Value] = job.TTL
+		}
+	}
+
+	if len(pending) == 0 {
+		return results, nil
+	}
+
+	var cacheable []string
+	for value, ttl := range ttls {
+		if ttl > 0 {
+			cacheable = append(cacheable, value)
+		}
+	}
+
+	var cached map[string]string
+	if r.Cache != nil && len(cacheable) > 0 {
+		var err error
+		if cached, err = r.Cache.Lookup(cacheable); err != nil {
+			// A broken cache must never block resolution
+			fmt.Fprintf(os.Stderr, text.Text.Formats.ErrorPrefix, err)
+			cached = nil
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		errs    []error
+		fetched = make(map[string]string)
+	)
+
+	for value := range pending {
+		if v, ok := cached[value]; ok {
+			fetched[value] = v
+			continue
+		}
+
+		wg.Add(1)
+		go func(value string) {
+			defer wg.Done()
+			v, err := fetch(value)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			fetched[value] = v
+		}(value)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	if r.Cache != nil {
+		fresh := make(map[string]time.Duration)
+		for _, value := range cacheable {
+			if _, hit := cached[value]; !hit {
+				fresh[value] = ttls[value]
+			}
+		}
+		if len(fresh) > 0 {
+			if err := r.Cache.Store(fetched, fresh); err != nil {
+				fmt.Fprintf(os.Stderr, text.Text.Formats.ErrorPrefix, err)
+			}
+		}
+	}
+
+	for value, indexes := range pending {
+		for _, i := range indexes {
+			results[i] = fetched[value]
+		}
+	}
+
+	return results, nil
+}
+
+// fetch resolves a single reference using its provider and timeout
+func fetch(value string) (string, error) {
+	scheme, ref, _ := ParseRef(value)
+	provider := Providers[scheme]
+
+	ctx, cancel := context.WithTimeout(context.Background(), provider.Timeout)
+	defer cancel()
+
+	v, err := provider.Fetch(ctx, ref)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf(text.Text.Errors.ResolveTimeout, scheme, provider.Timeout)
+		}
+		return "", fmt.Errorf(text.Text.Errors.ResolveFailed, scheme, err)
+	}
+	return v, nil
+}
+
+// fetchCommand runs ref with sh and returns its trimmed standard output
+func fetchCommand(ctx context.Context, ref string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", ref)
+	cmd.Stderr = &stderr
+	// Do not wait for grandchildren holding the output pipe after a timeout
+	cmd.WaitDelay = 100 * time.Millisecond
+
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
+
+// fetchFile returns the trimmed content of the file at ref ("~" expands to home)
+func fetchFile(ctx context.Context, ref string) (string, error) {
+	if rest, ok := strings.CutPrefix(ref, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		ref = filepath.Join(home, rest)
+	}
+
+	// A FIFO or a hung network mount can block the read forever, and it
+	// can't be interrupted; give up on it when the timeout expires
+	type result struct {
+		data []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		data, err := os.ReadFile(ref)
+		done <- result{data, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return "", r.err
+		}
+		return strings.TrimRight(string(r.data), "\r\n"), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// fetchEnv returns the value of the environment variable named ref
+func fetchEnv(_ context.Context, ref string) (string, error) {
+	v, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf(text.Text.Errors.ResolveEnvUnset, ref)
+	}
+	return v, nil
+}
@@ -0,0 +1,133 @@
+package resolve
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRef(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          string
+		expectedScheme string
+		expectedRef    string
+		expectedOk     bool
+	}{
+		{
+			name:           "command reference",
+			value:          "cmd://pass show api",
+			expectedScheme: "cmd",
+			expectedRef:    "pass show api",
+			expectedOk:     true,
+		},
+		{
+			name:           "file reference",
+			value:          "file://~/.secrets/key",
+			expectedScheme: "file",
+			expectedRef:    "~/.secrets/key",
+			expectedOk:     true,
+		},
+		{
+			name:       "literal url",
+			value:      "https://api.anthropic.com",
+			expectedOk: false,
+		},
+		{
+			name:       "plain literal",
+			value:      "sk-ant-xxxxx",
+			expectedOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme, ref, ok := ParseRef(tt.value)
+			assert.Equal(t, tt.expectedOk, ok, "ok should match")
+			assert.Equal(t, tt.expectedScheme, scheme, "scheme should match")
+			assert.Equal(t, tt.expectedRef, ref, "ref should match")
+		})
+	}
+}
+
+func TestResolveLiteralsAndReferences(t *testing.T) {
+	t.Setenv("ENVPICK_TEST_VALUE", "from-env")
+
+	resolver := &Resolver{}
+	values, err := resolver.Resolve([]Job{
+		{Value: "literal"},
+		{Value: "cmd://echo from-cmd"},
+		{Value: "env://ENVPICK_TEST_VALUE"},
+		{Value: "cmd://echo from-cmd"},
+	})
+	require.NoError(t, err, "Resolve should succeed")
+
+	assert.Equal(t, []string{"literal", "from-cmd", "from-env", "from-cmd"}, values)
+}
+
+func TestResolveConcurrently(t *testing.T) {
+	resolver := &Resolver{}
+
+	start := time.Now()
+	_, err := resolver.Resolve([]Job{
+		{Value: "cmd://sleep 0.3; echo a"},
+		{Value: "cmd://sleep 0.3; echo b"},
+		{Value: "cmd://sleep 0.3; echo c"},
+	})
+	require.NoError(t, err, "Resolve should succeed")
+
+	assert.Less(t, time.Since(start), 800*time.Millisecond, "references should resolve in parallel")
+}
+
+func TestResolveTimeout(t *testing.T) {
+	original := Providers["cmd"]
+	Providers["cmd"] = Provider{Timeout: 100 * time.Millisecond, Fetch: original.Fetch}
+	defer func() { Providers["cmd"] = original }()
+
+	resolver := &Resolver{}
+	_, err := resolver.Resolve([]Job{{Value: "cmd://sleep 5"}})
+	require.Error(t, err, "slow provider should time out")
+	assert.Contains(t, err.Error(), "timed out")
+}
+
+func TestResolveError(t *testing.T) {
+	resolver := &Resolver{}
+	_, err := resolver.Resolve([]Job{{Value: "env://ENVPICK_TEST_UNSET_VARIABLE"}})
+	require.Error(t, err, "unset variable should fail")
+	assert.Contains(t, err.Error(), "ENVPICK_TEST_UNSET_VARIABLE")
+}
+
+func TestResolveUsesCache(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	resolver := &Resolver{Cache: cache}
+
+	calls := 0
+	original := Providers["cmd"]
+	Providers["cmd"] = Provider{
+		Timeout: time.Second,
+		Fetch: func(ctx context.Context, ref string) (string, error) {
+			calls++
+			return original.Fetch(ctx, ref)
+		},
+	}
+	defer func() { Providers["cmd"] = original }()
+
+	job := Job{Value: "cmd://echo secret", TTL: time.Hour}
+
+	values, err := resolver.Resolve([]Job{job})
+	require.NoError(t, err, "first Resolve should succeed")
+	assert.Equal(t, []string{"secret"}, values)
+
+	values, err = resolver.Resolve([]Job{job})
+	require.NoError(t, err, "second Resolve should succeed")
+	assert.Equal(t, []string{"secret"}, values)
+	assert.Equal(t, 1, calls, "second resolve should be served from cache")
+
+	// Without a TTL the cache is bypassed
+	_, err = resolver.Resolve([]Job{{Value: job.Value}})
+	require.NoError(t, err, "uncached Resolve should succeed")
+	assert.Equal(t, 2, calls, "uncached resolve should call the provider")
+}
@@ -0,0 +1,28 @@
+//go:build unix
+
+package resolve
+
+import (
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveFileTimeout(t *testing.T) {
+	original := Providers["file"]
+	Providers["file"] = Provider{Timeout: 100 * time.Millisecond, Fetch: original.Fetch}
+	defer func() { Providers["file"] = original }()
+
+	// Opening a FIFO without a writer blocks until the timeout
+	fifo := filepath.Join(t.TempDir(), "fifo")
+	require.NoError(t, syscall.Mkfifo(fifo, 0600))
+
+	resolver := &Resolver{}
+	_, err := resolver.Resolve([]Job{{Value: "file://" + fifo}})
+	require.Error(t, err, "blocked read should time out")
+	assert.Contains(t, err.Error(), "timed out")
+}
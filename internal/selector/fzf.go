@@ -12,8 +12,9 @@ import (
 
 // Option represents a selectable option in fzf
 type Option struct {
-	Name   string
-	Status string // "active" or empty
+	Name      string
+	Status    string // "active" or empty
+	Protected bool   // shown with a warning marker
 }
 
 // runFzf executes fzf with the given input and prompt, returning the selected line
@@ -22,7 +23,7 @@ func runFzf(input, prompt string) (string, error) {
 		return "", errors.New(text.Text.Errors.FzfNotFound)
 	}
 
-	cmd := exec.Command("fzf", "--prompt", prompt+text.Text.Formats.PromptSuffix, "--no-multi", "--height=40%", "--reverse")
+	cmd := exec.Command("fzf", "--prompt", prompt+text.Text.Formats.PromptSuffix, "--no-multi", "--ansi", "--height=40%", "--reverse")
 	cmd.Stdin = strings.NewReader(input)
 	cmd.Stderr = os.Stderr
 
@@ -68,6 +69,9 @@ func formatOption(opt Option) string {
 	if opt.Status == "active" {
 		line += text.Text.Formats.ActiveIndicator
 	}
+	if opt.Protected {
+		line += text.Text.Formats.ProtectedIndicator
+	}
 
 	return line
 }
@@ -9,64 +9,318 @@ type CommandText struct {
 
 // CommandsText contains all command-related text.
 type CommandsText struct {
-	Root      CommandText
-	Use       CommandText
-	Env       CommandText
-	EnvSelect CommandText
-	Edit      CommandText
-	Web       CommandText
-	Init      CommandText
-	InitZsh   CommandText
-	Flags     FlagsText
+	Root         CommandText
+	Use          CommandText
+	Env          CommandText
+	EnvSelect    CommandText
+	Edit         CommandText
+	Web          CommandText
+	Init         CommandText
+	InitZsh      CommandText
+	Cache        CommandText
+	CacheClear   CommandText
+	Share        CommandText
+	Receive      CommandText
+	Identity     CommandText
+	Layers       CommandText
+	LayersSign   CommandText
+	Show         CommandText
+	List         CommandText
+	Status       CommandText
+	Which        CommandText
+	Diff         CommandText
+	Get          CommandText
+	Set          CommandText
+	Unset        CommandText
+	New          CommandText
+	Cp           CommandText
+	Mv           CommandText
+	Rm           CommandText
+	Import       CommandText
+	ImportDotenv CommandText
+	Capture      CommandText
+	Export       CommandText
+	SyncEnv      CommandText
+	Shell        CommandText
+	Run          CommandText
+	History      CommandText
+	Off          CommandText
+	Migrate      CommandText
+	Flags        FlagsText
 }
 
 // FlagsText contains flag descriptions.
 type FlagsText struct {
-	Namespace string
+	Namespace        string
+	AllNamespaces    string
+	ShareTo          string
+	ShareOutput      string
+	ReceiveAs        string
+	ReceiveFrom      string
+	Yes              string
+	Reveal           string
+	ListAll          string
+	ListTree         string
+	ListFormat       string
+	StatusFormat     string
+	DiffFormat       string
+	ValueAll         string
+	FromStdin        string
+	Raw              string
+	NewFrom          string
+	ImportAs         string
+	ImportPolicy     string
+	MarkSecrets      string
+	CapturePrefix    string
+	CaptureKeys      string
+	ExportFormat     string
+	ExportOutput     string
+	NoHooks          string
+	ConfirmProtected string
+	Reason           string
+	UseFor           string
+	OffAll           string
+	MigrateDryRun    string
+	UseSession       string
+	EnvFollow        string
+	InitFollow       string
 }
 
 // ErrorsText contains all error messages.
 type ErrorsText struct {
-	ConfigHomeDir       string
-	ConfigFileNotFound  string
-	ConfigFileRead      string
-	ConfigFileParse     string
-	ConfigNotFound      string
-	ConfigNoWebURL      string
-	StateFileRead       string
-	StateFileParse      string
-	StateEncode         string
-	StateFileWrite      string
-	FzfNotFound         string
-	FzfFailed           string
-	SelectionCancelled  string
-	NoSelectionMade     string
-	NoOptionsAvailable  string
-	NoConfigurations    string
-	NoConfigurationsUse string
-	BrowserOpenFailed   string
-	UnsupportedPlatform string
+	ConfigHomeDir            string
+	ConfigFileNotFound       string
+	ConfigFileRead           string
+	ConfigFileParse          string
+	ConfigNotFound           string
+	ConfigNoWebURL           string
+	StateFileRead            string
+	StateFileParse           string
+	StateEncode              string
+	StateFileWrite           string
+	FzfNotFound              string
+	FzfFailed                string
+	SelectionCancelled       string
+	NoSelectionMade          string
+	NoOptionsAvailable       string
+	NoConfigurations         string
+	NoConfigurationsUse      string
+	BrowserOpenFailed        string
+	UnsupportedPlatform      string
+	ConfigInvalidCacheTTL    string
+	ResolveTimeout           string
+	ResolveFailed            string
+	ResolveEnvUnset          string
+	CacheRead                string
+	CacheWrite               string
+	CacheCorrupt             string
+	CacheClear               string
+	IdentityParse            string
+	IdentityRead             string
+	IdentityCreate           string
+	FingerprintKeyRead       string
+	FingerprintKeyCreate     string
+	PublicKeyInvalid         string
+	ConfigFileWrite          string
+	ConfigTableNotEditable   string
+	ShareInvalidRecipient    string
+	BundleSeal               string
+	BundleOpen               string
+	BundleRead               string
+	BundleWrite              string
+	BundleBadSignature       string
+	BundleUnexpectedSigner   string
+	Aborted                  string
+	TeamConfigRead           string
+	TeamConfigParse          string
+	TeamConfigUnsigned       string
+	TeamConfigVerify         string
+	TeamConfigNoTrustedKeys  string
+	TeamConfigBadSignature   string
+	TeamConfigSign           string
+	TrustedKeysRead          string
+	NoCurrentConfig          string
+	UnknownFormat            string
+	KeyNotFound              string
+	KeyNotFoundAll           string
+	InvalidKey               string
+	SetMissingValue          string
+	ValueAndStdin            string
+	StdinRead                string
+	InvalidConfigName        string
+	ConfigExists             string
+	ConfigNameClash          string
+	UnknownImportPolicy      string
+	DotenvRead               string
+	DotenvParse              string
+	DotenvEmpty              string
+	DotenvInvalidKey         string
+	DotenvMissingEquals      string
+	DotenvUnterminated       string
+	DotenvTrailingText       string
+	CaptureNoFilter          string
+	CaptureNothing           string
+	ExportUnknownFormat      string
+	ExportMultiline          string
+	ExportWrite              string
+	ActiveEnvWrite           string
+	ShellStart               string
+	NoCommands               string
+	CommandNotFound          string
+	CommandStart             string
+	ConfigInvalidHookFailure string
+	ConfigInvalidHookTimeout string
+	HookFailed               string
+	HookTimeout              string
+	ConfigInvalidBool        string
+	AuditWrite               string
+	ProtectedNotConfirmed    string
+	ReasonRequired           string
+	NoPreviousConfig         string
+	StateLock                string
+	StateRecover             string
+	InvalidFileVersion       string
+	FileVersionTooNew        string
+	MigrateFailed            string
+	NoSession                string
+	InvalidSession           string
+	SessionWithLease         string
+	InvalidLeaseDuration     string
+	BundleUntrustedSigner    string
+	TrustedSignersRead       string
+	TrustedSignersWrite      string
+	ReservedConfigName       string
+	CacheLock                string
+	CaptureInvalidKeys       string
+	NoEditableConfigs        string
 }
 
 // MessagesText contains informational messages.
 type MessagesText struct {
-	SwitchedToConfig   string
-	SwitchedToConfigNS string
-	OpenedURL          string
+	SwitchedToConfig        string
+	SwitchedToConfigNS      string
+	OpenedURL               string
+	CacheCleared            string
+	SharedBundle            string
+	ReceivedBundle          string
+	ReceiveConflict         string
+	WroteProfile            string
+	IdentityRecipient       string
+	IdentitySigner          string
+	TeamLayerVerified       string
+	TeamLayerNone           string
+	TeamConfigSigned        string
+	TeamConfigTrustHint     string
+	NoActiveConfigs         string
+	NoDifferences           string
+	SetValue                string
+	UnsetValue              string
+	CreatedConfig           string
+	CopiedConfig            string
+	RenamedConfig           string
+	RemovedConfig           string
+	ImportNewProfile        string
+	ImportNoChanges         string
+	CaptureMissingKey       string
+	CapturePreview          string
+	ExportedConfig          string
+	SyncedActiveEnv         string
+	ActiveEnvSyncFailed     string
+	NestedShell             string
+	EnteringShell           string
+	LeftShell               string
+	HookWarning             string
+	LeaseUntil              string
+	LeaseExpired            string
+	LeaseExpiredRestored    string
+	NoHistory               string
+	DeactivatedConfig       string
+	NothingToDeactivate     string
+	StateRecovered          string
+	MigrateStateCurrent     string
+	MigrateConfigDefault    string
+	MigrateUpToDate         string
+	MigratePending          string
+	Migrated                string
+	SwitchedToConfigSession string
+	WhichUnresolved         string
+	UntrustedSigner         string
+	TrustedSigner           string
+	BundleExecutable        string
+	AuditFailed             string
+	TeamLayerIgnored        string
+	SetSkippedTeam          string
 }
 
 // FormatsText contains formatting strings.
 type FormatsText struct {
-	ErrorPrefix     string
-	ActiveIndicator string
-	ExportStatement string
-	PromptSuffix    string
+	ErrorPrefix        string
+	ActiveIndicator    string
+	ExportStatement    string
+	PromptSuffix       string
+	BundleExtension    string
+	KeyAdded           string
+	KeyRemoved         string
+	KeyChanged         string
+	LayersConfig       string
+	LayersKey          string
+	LayersRejected     string
+	ShowHeader         string
+	ShowVariables      string
+	ShowCommands       string
+	ShowMetadata       string
+	ShowRow            string
+	ShowVia            string
+	MaskedValue        string
+	EmptyValue         string
+	ListTableHeader    string
+	ListTableRow       string
+	DefaultNamespace   string
+	ActiveMarker       string
+	TreeBranch         string
+	TreeLastBranch     string
+	TreeIndent         string
+	TreeLastIndent     string
+	StatusTableHeader  string
+	StatusTableRow     string
+	StatusCount        string
+	WhichTableHeader   string
+	WhichTableRow      string
+	WhichScore         string
+	NoMatch            string
+	DiffHeader         string
+	DiffAdded          string
+	DiffRemoved        string
+	DiffChanged        string
+	UnifiedFrom        string
+	UnifiedTo          string
+	UnifiedHunk        string
+	Assignment         string
+	GetAllRow          string
+	CaptureRow         string
+	ActiveEnvHeader    string
+	ProtectedIndicator string
+	UnsetStatement     string
+	LeaseExpiredStatus string
+	HistoryTableHeader string
+	HistoryTableRow    string
+	HistoryTime        string
+	MigrationStep      string
+	SessionSuffix      string
+	KeyExecutable      string
 }
 
 // PromptsText contains interactive prompts.
 type PromptsText struct {
 	SelectConfiguration string
 	SelectWebURL        string
+	ConfirmWriteProfile string
+	ConfirmReveal       string
+	ConfirmRemoveConfig string
+	SelectCommand       string
+	TypeProtectedName   string
+	EnterReason         string
+	ConfirmTrustSigner  string
 }
 
 // TextData contains all user-facing text for the envpick application.
@@ -89,9 +343,36 @@ var Text = TextData{
 			Long:  `Manage multiple environment variable configurations through a simple config file and interactive commands.`,
 		},
 		Use: CommandText{
-			Use:   "use",
+			Use:   "use [config-name | -]",
 			Short: "Switch configuration persistently",
-			Long:  `Select a configuration to persist across new terminal sessions.`,
+			Long: `Select a configuration to persist across new terminal sessions.
+
+Without a name, fzf lists the current configuration first, then the previous
+one, then the others by how often and how recently they were selected.
+'envpick use -' switches back to the previous configuration.
+
+Also updates ~/.envpick/active.env and the environment.d file for tools
+that don't run through your shell (see 'envpick sync-env').
+
+Runs the _on_deactivate hook of the previous configuration and the
+_on_activate hook of the new one, with $ENVPICK_PREV and $ENVPICK_NEXT set.
+A failing hook cancels the switch unless _hook_failure = "warn";
+_hook_timeout limits how long a hook may run (default 30s).
+
+Protected configurations (_protected = "true") must be confirmed by typing
+their name, or with --yes. The same applies to 'ep tmp' (and 'envpick env
+select'), 'envpick shell' and 'envpick run', which is how a command is executed
+with a configuration. With _require_reason = "true", a reason is asked
+for (or taken from --reason), except with --session. The activation is
+written to ~/.envpick/audit.log once hooks ran and the switch was saved.
+
+With --for, the selection is a lease: once it expires, 'envpick env' switches
+back to the previous selection (or none) and the shell integration unsets the
+variables in terminals that are already open.
+
+With --session, the selection applies to this terminal only ($ENVPICK_SESSION,
+set by the shell integration) and ends when the shell exits; 'ep tmp' uses it.
+Other terminals and new shells keep the persisted selection.`,
 		},
 		Env: CommandText{
 			Use:   "env",
@@ -99,7 +380,13 @@ var Text = TextData{
 			Long: `Output the current configuration's environment variables as shell export statements.
 
 Usage in shell profile (.zshrc, .bashrc):
-  eval "$(envpick env)"`,
+  eval "$(envpick env)"
+  eval "$(envpick env --all-namespaces)"
+
+The output also records the persisted selections in $ENVPICK_SELECTIONS.
+With --follow, it only switches the namespaces whose selection changed since:
+the variables the new selection doesn't set are unset. Nothing is output when
+this terminal has a selection of its own ('envpick use --session').`,
 		},
 		EnvSelect: CommandText{
 			Use:   "select [config-name]",
@@ -107,6 +394,9 @@ Usage in shell profile (.zshrc, .bashrc):
 			Long: `Output exports for a configuration without persisting.
 Prompts interactively if config-name is omitted.
 
+Runs activation hooks like 'envpick use', with the persisted configuration
+as the previous one. Hook output goes to stderr.
+
 Usage:
   eval "$(envpick env select myconfig)"
   eval "$(envpick env select)"`,
@@ -140,14 +430,453 @@ Add to ~/.zshrc:
 
 Sets up completion, auto-loading, and 'ep' helper:
   ep use [flags]        - Persistent selection
-  ep tmp [flags] [name] - Temporary selection
+  ep tmp [flags] [name] - Selection for this terminal only
   ep <other>            - Pass through to envpick
 
+With --follow, open shells also pick up 'envpick use' from other terminals,
+in every namespace, before their next prompt, unless they have a selection
+of their own (ep tmp):
+  eval "$(envpick init zsh --follow)"
+
 Reload shell after adding:
   source ~/.zshrc`,
+		},
+		Cache: CommandText{
+			Use:   "cache",
+			Short: "Manage the resolved-value cache",
+			Long: `Manage the encrypted cache of values resolved from providers.
+
+Values referencing a provider are resolved on every use:
+  API_KEY = "cmd://pass show api/key"   # output of a shell command
+  API_KEY = "file://~/.secrets/api-key" # content of a file
+  API_KEY = "env://OTHER_VARIABLE"      # another environment variable
+
+Set _cache_ttl on a configuration to cache its resolved values:
+  _cache_ttl = "8h"`,
+		},
+		CacheClear: CommandText{
+			Use:   "clear",
+			Short: "Remove all cached values",
+			Long:  `Remove the resolved-value cache and its encryption key.`,
+		},
+		Share: CommandText{
+			Use:   "share <config-name>",
+			Short: "Share a configuration as an encrypted bundle",
+			Long: `Write a configuration to a bundle file encrypted to a teammate's age
+public key and signed with your signing key.
+
+Provider references are resolved first, so the teammate receives the
+actual values.
+
+Usage:
+  envpick share work --to age1...
+  envpick share -n db staging --to age1... -o staging.age`,
+		},
+		Receive: CommandText{
+			Use:   "receive <file>",
+			Short: "Add a configuration from a shared bundle",
+			Long: `Decrypt a bundle with your identity, verify its signature and write it
+to config.toml as a configuration.
+
+Shows which keys will be added or changed, and verbatim the hooks, commands
+and provider references (cmd://, file://, env://) that run once the
+configuration is used, then asks before writing.
+Run 'envpick identity' to get the public key to give to the sender.
+
+The signature only proves who holds the key inside the bundle. The signer
+must match --from, be listed in ~/.envpick/trusted_signers (one fingerprint
+per line), or be confirmed when asked, which also adds it to that file;
+--yes never trusts a new signer.
+
+Usage:
+  envpick receive work.envpick.age --from 5a566f23e8329623
+  envpick receive work.envpick.age --as work-shared`,
+		},
+		Identity: CommandText{
+			Use:   "identity",
+			Short: "Show your public keys for sharing",
+			Long: `Show the age public key teammates use to share bundles with you, and the
+public key and fingerprint of your signing key.
+
+Both keys are created in ~/.envpick on first use.`,
+		},
+		Layers: CommandText{
+			Use:   "layers [config-name]",
+			Short: "Show which config layer each value came from",
+			Long: `Show, for each configuration, whether every key comes from the team
+config or your personal config.toml.
+
+The team config is read from $ENVPICK_TEAM_CONFIG or /etc/envpick/team.toml.
+It must be signed ('envpick layers sign') by a key listed in
+~/.envpick/team_keys, otherwise envpick refuses to load it. While
+team_keys lists no key, the team config is ignored with a warning.
+
+In the team config, _overridable lists the keys personal config may
+override ("*" allows all, "_commands" the whole [name._commands] table).
+Keys the team config doesn't define, such as your own token, can always
+be added:
+
+  [work]
+  ANTHROPIC_BASE_URL = "https://api.company.com"
+  ANTHROPIC_MODEL = "claude-sonnet-4-5"
+  _overridable = "ANTHROPIC_MODEL"`,
+		},
+		LayersSign: CommandText{
+			Use:   "sign <team-config>",
+			Short: "Sign a team config",
+			Long: `Sign a team config with your signing key, writing <team-config>.sig.
+
+Everyone loading the team config must add your public key to
+~/.envpick/team_keys.`,
+		},
+		Show: CommandText{
+			Use:   "show [config-name]",
+			Short: "Show a configuration with secrets masked",
+			Long: `Show the resolved variables and metadata of a configuration (default: the
+current one).
+
+Values of keys ending in _KEY, _TOKEN or _SECRET, and of keys listed in
+_secret_keys, are masked. A short fingerprint is shown instead, so two
+configurations can be compared without revealing their values. Fingerprints
+are keyed with ~/.envpick/fingerprint.key and only match on this machine.
+
+Usage:
+  envpick show
+  envpick show work
+  envpick show -n db prod --reveal`,
+		},
+		List: CommandText{
+			Use:   "list",
+			Short: "List configurations",
+			Long: `List configurations, marking the active one of each namespace.
+
+Usage:
+  envpick list                 # default namespace
+  envpick list -n db           # db namespace
+  envpick list --all           # every namespace
+  envpick list --tree          # hierarchy of namespaces
+  envpick list --all --format json
+  envpick list --format names  # one name per line, for scripts`,
+		},
+		Status: CommandText{
+			Use:   "status",
+			Short: "Show active configurations and whether this shell has them",
+			Long: `Show the persisted configuration of every namespace and whether the
+current shell environment matches it:
+
+  applied  every variable matches
+  partial  some variables are missing or different
+  drifted  no variable matches
+  missing  the configuration no longer exists
+
+Differing keys are listed; values are never shown.
+
+Usage:
+  envpick status
+  envpick status --format json`,
+		},
+		Which: CommandText{
+			Use:   "which",
+			Short: "Detect the active configuration from the environment",
+			Long: `Compare the current environment with every configuration and report the
+best match per namespace, with the share of its variables that match.
+
+Keys whose live value differs from the persisted configuration are
+listed; values are never shown.
+
+References (cmd://, file://, env://) are only resolved for variables that
+are set, of configurations that could still be the best match. A
+configuration whose references fail is skipped with a warning.
+
+Usage:
+  envpick which
+  envpick which -n db
+  envpick which --format json`,
+		},
+		Diff: CommandText{
+			Use:   "diff <config-name> [config-name]",
+			Short: "Compare two configurations",
+			Long: `Show the keys added, removed and changed between two configurations.
+With a single name, compare the current configuration of its namespace
+with it. Names include their namespace (db.prod) unless -n is given.
+
+Secret values are masked by fingerprint.
+
+Usage:
+  envpick diff prod                  # current -> prod
+  envpick diff personal work
+  envpick diff db.local db.prod
+  envpick diff -n db local prod --format unified
+  envpick diff prod --format json`,
+		},
+		Get: CommandText{
+			Use:   "get <config-name> <KEY>",
+			Short: "Print a value of a configuration",
+			Long: `Print the value of a key in a configuration. Provider references are
+resolved unless --raw is given.
+
+Usage:
+  envpick get work ANTHROPIC_MODEL
+  envpick get work ANTHROPIC_AUTH_TOKEN --raw
+  envpick get --all -n api ANTHROPIC_MODEL`,
+		},
+		Set: CommandText{
+			Use:   "set <config-name> <KEY=VALUE>",
+			Short: "Set a value in a configuration",
+			Long: `Set a key in a configuration, editing config.toml in place so that
+comments and layout are preserved.
+
+Use --from-stdin to keep secrets out of shell history. With --all,
+configurations that only exist in the team config are skipped.
+
+Usage:
+  envpick set work ANTHROPIC_MODEL=claude-opus-4-5
+  envpick set work ANTHROPIC_AUTH_TOKEN --from-stdin < token.txt
+  envpick set --all -n api API_TIMEOUT_MS=600000`,
+		},
+		Unset: CommandText{
+			Use:   "unset <config-name> <KEY>",
+			Short: "Remove a key from a configuration",
+			Long: `Remove a key from a configuration, editing config.toml in place.
+
+Usage:
+  envpick unset work ANTHROPIC_API_KEY
+  envpick unset --all -n api LEGACY_FLAG`,
+		},
+		New: CommandText{
+			Use:   "new <config-name> [KEY=VALUE...]",
+			Short: "Create a configuration",
+			Long: `Add a configuration to config.toml, optionally with initial values or as a
+copy of an existing configuration.
+
+Usage:
+  envpick new staging
+  envpick new staging ANTHROPIC_BASE_URL=https://staging.example.com
+  envpick new -n db staging --from prod`,
+		},
+		Cp: CommandText{
+			Use:   "cp <config-name> <new-name>",
+			Short: "Copy a configuration",
+			Long: `Copy a configuration, including its comments, to a new name in config.toml.
+
+Usage:
+  envpick cp work work-backup
+  envpick cp -n db prod staging`,
+		},
+		Mv: CommandText{
+			Use:   "mv <config-name> <new-name>",
+			Short: "Rename a configuration",
+			Long: `Rename a configuration in config.toml, keeping its position and comments.
+
+If the configuration is selected, the selection follows the new name.
+Moving it to another namespace clears the selection.
+
+Usage:
+  envpick mv work work-old
+  envpick mv -n db prod production`,
+		},
+		Rm: CommandText{
+			Use:   "rm <config-name>",
+			Short: "Remove a configuration",
+			Long: `Remove a configuration from config.toml. Asks for confirmation unless
+--yes is given.
+
+If the configuration is selected, the selection is cleared.
+
+Usage:
+  envpick rm work-old
+  envpick rm -n db staging --yes`,
+		},
+		Import: CommandText{
+			Use:   "import",
+			Short: "Import configurations from other formats",
+			Long:  `Import configurations from files in other formats into config.toml.`,
+		},
+		ImportDotenv: CommandText{
+			Use:   "dotenv <file> --as <config-name>",
+			Short: "Import a configuration from a .env file",
+			Long: `Read a .env file into a new or existing configuration.
+
+Supports comments, export prefixes, single, double and backtick quotes,
+escapes in double quotes and multi-line quoted values.
+
+Keys already in the configuration are handled by --policy:
+  merge      values from the file replace existing ones (default)
+  keep       existing values are kept, only new keys are added
+  overwrite  variables missing from the file are removed
+
+Shows the keys that will be added, changed or removed and asks before
+writing. With --mark-secrets, keys that look like secrets (e.g.
+DB_PASSWORD, or URLs with a password) are added to _secret_keys.
+
+Usage:
+  envpick import dotenv .env --as dev
+  envpick import dotenv .env.staging -n api --as staging --mark-secrets`,
+		},
+		Capture: CommandText{
+			Use:   "capture <config-name>",
+			Short: "Save variables from the current shell as a configuration",
+			Long: `Create a configuration from the environment of the current shell.
+
+Variables are selected by name prefix (--prefix, repeatable) or listed
+explicitly (--keys). Shell bookkeeping variables such as PWD, SHLVL and _,
+envpick's own ENVPICK_ variables and names starting with _ (metadata) are
+never captured; listing one with --keys is an error.
+
+Shows the variables that will be written, with secrets masked, and asks
+before writing.
+
+Usage:
+  envpick capture work --prefix ANTHROPIC_ --prefix API_
+  envpick capture -n db staging --keys DB_HOST,DB_PORT,DB_USER`,
+		},
+		Export: CommandText{
+			Use:   "export [config-name]",
+			Short: "Export a configuration for other tools",
+			Long: `Write the resolved variables of a configuration (default: the current one)
+in a format other tools read:
+
+  dotenv   .env file
+  json     JSON object
+  yaml     YAML mapping
+  docker   file for docker run --env-file (no multi-line values)
+  systemd  file for systemd EnvironmentFile=
+  k8s      Kubernetes ConfigMap, plus a Secret for secret keys
+  github   lines to append to $GITHUB_ENV in GitHub Actions
+
+Secret keys are those ending in _KEY, _TOKEN or _SECRET and those listed
+in _secret_keys. Files written with -o are only readable by you.
+
+Usage:
+  envpick export work -f docker -o work.env
+  envpick export -n db prod -f k8s | kubectl apply -f -
+  envpick export ci -f github >> "$GITHUB_ENV"`,
+		},
+		SyncEnv: CommandText{
+			Use:   "sync-env",
+			Short: "Write the active configurations to files for non-shell tools",
+			Long: `Write the variables of the selected configuration of every namespace to:
+
+  ~/.envpick/active.env                        dotenv, for IDEs and cron jobs
+  ~/.config/environment.d/60-envpick.conf     for systemd user services
+
+Both files are replaced atomically and are only readable by you.
+'envpick use' updates them automatically; run sync-env after editing
+values or to recreate the files.`,
+		},
+		Shell: CommandText{
+			Use:   "shell <config-name>",
+			Short: "Start a subshell with a configuration applied",
+			Long: `Start $SHELL with the variables of a configuration applied and
+ENVPICK_SHELL set to its name, e.g. for your prompt. Exit the subshell to
+return to your original environment, which is left untouched; envpick exits
+with the exit status of the subshell. A protected configuration must be confirmed first, by typing its name or with
+--yes.
+
+Usage:
+  envpick shell prod
+  envpick shell -n db staging`,
+		},
+		Run: CommandText{
+			Use:   "run <config-name> [command] [args...]",
+			Short: "Run a named command of a configuration",
+			Long: `Run a command from the configuration's _commands table with the
+configuration's variables applied. Without a command name, pick one with fzf.
+
+Commands run with sh -c; extra arguments are available as "$@". Options of
+envpick itself go before the configuration name, everything after it is
+passed to the command.
+
+A protected configuration must be confirmed first, by typing its name or with
+--yes before the configuration name.
+
+  [db.staging]
+  DATABASE_URL = "postgres://staging.internal/app"
+
+  [db.staging._commands]
+  console = "psql $DATABASE_URL"
+  logs = "kubectl logs -f deploy/api"
+
+Usage:
+  envpick run db.staging console
+  envpick run -n db staging
+  envpick run work deploy --dry-run`,
+		},
+		History: CommandText{
+			Use:   "history",
+			Short: "List past configuration switches",
+			Long: `List the configurations selected with 'envpick use', most recent first.
+
+Usage:
+  envpick history        # default namespace
+  envpick history -n db  # db namespace
+
+Switch back to the previous configuration with 'envpick use -'.`,
+		},
+		Off: CommandText{
+			Use:   "off",
+			Short: "Deactivate the current configuration",
+			Long: `Clear the persisted selection so that new shells no longer export it, and
+output unset statements for its variables.
+
+Usage in shell:
+  eval "$(envpick off)"        # default namespace
+  eval "$(envpick off -n db)"  # db namespace
+  eval "$(envpick off --all)"  # every namespace, e.g. after a key leak
+
+'ep off' does this for the current shell. Other open shells keep their
+variables until they are restarted.
+
+Runs the _on_deactivate hook of each cleared configuration unless --no-hooks.
+The selection is cleared first; a failing hook is only reported.`,
+		},
+		Migrate: CommandText{
+			Use:   "migrate",
+			Short: "Upgrade config.toml and state.toml to the current format",
+			Long: `Upgrade ~/.envpick/config.toml and state.toml to the format of this version
+of envpick, keeping the comments and layout of config.toml.
+
+Older formats are still read without migrating; migrating writes the
+'version' key so that future upgrades know where to start.
+
+Usage:
+  envpick migrate --dry-run  # show the pending changes as a diff
+  envpick migrate`,
 		},
 		Flags: FlagsText{
-			Namespace: "filter configurations by namespace (e.g., 'db' for db.local, db.prod)",
+			Namespace:        "filter configurations by namespace (e.g., 'db' for db.local, db.prod)",
+			AllNamespaces:    "output the current configuration of every namespace",
+			ShareTo:          "age public key of the recipient (age1...)",
+			ShareOutput:      "bundle file to write (default: <config-name>.envpick.age)",
+			ReceiveAs:        "configuration name to write instead of the bundled one",
+			ReceiveFrom:      "require the bundle to be signed by this fingerprint",
+			Yes:              "do not ask for confirmation",
+			Reveal:           "show secret values in clear text (asks for confirmation)",
+			ListAll:          "list configurations of every namespace",
+			ListTree:         "show namespaces as a tree",
+			ListFormat:       "output format: table, names or json",
+			StatusFormat:     "output format: table or json",
+			DiffFormat:       "output format: text, unified or json",
+			ValueAll:         "apply to every configuration of the namespace",
+			FromStdin:        "read the value from standard input",
+			Raw:              "print provider references instead of resolving them",
+			NewFrom:          "copy values from an existing configuration",
+			ImportAs:         "configuration to import into",
+			ImportPolicy:     "how to handle existing keys: merge, keep or overwrite",
+			MarkSecrets:      "add keys that look like secrets to _secret_keys",
+			CapturePrefix:    "capture variables starting with this prefix (repeatable)",
+			CaptureKeys:      "capture these variables (comma-separated)",
+			ExportFormat:     "output format: dotenv, json, yaml, docker, systemd, k8s or github",
+			ExportOutput:     "file to write instead of standard output",
+			NoHooks:          "do not run _on_activate and _on_deactivate hooks",
+			ConfirmProtected: "activate protected configurations without typing their name",
+			Reason:           "reason for switching to a protected configuration (recorded in the audit log)",
+			UseFor:           "revert to the previous selection after this long (e.g. 30m, 2h)",
+			OffAll:           "deactivate the configurations of every namespace",
+			MigrateDryRun:    "show what would change without writing",
+			UseSession:       "select for this terminal session only ($ENVPICK_SESSION), until the shell exits",
+			EnvFollow:        "only switch namespaces whose persisted selection changed since $ENVPICK_SELECTIONS",
+			InitFollow:       "re-apply switches made in other terminals before each prompt",
 		},
 	},
 	Errors: ErrorsText{
@@ -166,37 +895,230 @@ Create it with your configurations:
 
 Variables with _ prefix are metadata.
 Run 'envpick edit' to create the file.`,
-		ConfigFileRead:      "failed to read config file: %w",
-		ConfigFileParse:     "failed to parse config file: %w",
-		ConfigNotFound:      "configuration %q not found",
-		ConfigNoWebURL:      "configuration %q has no web URL",
-		StateFileRead:       "failed to read state file: %w",
-		StateFileParse:      "failed to parse state file: %w",
-		StateEncode:         "failed to encode state: %w",
-		StateFileWrite:      "failed to write state file: %w",
-		FzfNotFound:         "fzf not found: install fzf for interactive selection",
-		FzfFailed:           "fzf failed: %w",
-		SelectionCancelled:  "selection cancelled",
-		NoSelectionMade:     "no selection made",
-		NoOptionsAvailable:  "no options available",
-		NoConfigurations:    "no configurations found",
-		NoConfigurationsUse: "no available configurations",
-		BrowserOpenFailed:   "failed to open browser: %w",
-		UnsupportedPlatform: "unsupported platform: %s",
+		ConfigFileRead:           "failed to read config file: %w",
+		ConfigFileParse:          "failed to parse config file: %w",
+		ConfigNotFound:           "configuration %q not found",
+		ConfigNoWebURL:           "configuration %q has no web URL",
+		StateFileRead:            "failed to read state file: %w",
+		StateFileParse:           "failed to parse state file: %w",
+		StateEncode:              "failed to encode state: %w",
+		StateFileWrite:           "failed to write state file: %w",
+		FzfNotFound:              "fzf not found: install fzf for interactive selection",
+		FzfFailed:                "fzf failed: %w",
+		SelectionCancelled:       "selection cancelled",
+		NoSelectionMade:          "no selection made",
+		NoOptionsAvailable:       "no options available",
+		NoConfigurations:         "no configurations found",
+		NoConfigurationsUse:      "no available configurations",
+		BrowserOpenFailed:        "failed to open browser: %w",
+		UnsupportedPlatform:      "unsupported platform: %s",
+		ConfigInvalidCacheTTL:    "configuration %q has invalid _cache_ttl: %w",
+		ResolveTimeout:           "%s provider timed out after %s",
+		ResolveFailed:            "%s provider failed: %w",
+		ResolveEnvUnset:          "environment variable %s is not set",
+		CacheRead:                "failed to read cache: %w",
+		CacheWrite:               "failed to write cache: %w",
+		CacheCorrupt:             "cache file is corrupt",
+		CacheClear:               "failed to clear cache: %w",
+		IdentityParse:            "failed to parse key %s: %v",
+		IdentityRead:             "failed to read key: %w",
+		IdentityCreate:           "failed to create key: %w",
+		FingerprintKeyRead:       "failed to read fingerprint key: %w",
+		FingerprintKeyCreate:     "failed to create fingerprint key: %w",
+		PublicKeyInvalid:         "invalid public key: %w",
+		ConfigFileWrite:          "failed to write config file: %w",
+		ConfigTableNotEditable:   "configuration %q is not a [table] section in config.toml and cannot be edited",
+		ShareInvalidRecipient:    "invalid recipient: %w",
+		BundleSeal:               "failed to create bundle: %w",
+		BundleOpen:               "failed to open bundle: %w",
+		BundleRead:               "failed to read bundle: %w",
+		BundleWrite:              "failed to write bundle: %w",
+		BundleBadSignature:       "bundle signature is invalid",
+		BundleUnexpectedSigner:   "bundle is signed by %s, expected %s",
+		Aborted:                  "aborted",
+		TeamConfigRead:           "failed to read team config: %w",
+		TeamConfigParse:          "failed to parse team config: %w",
+		TeamConfigUnsigned:       "team config %s has no signature file; sign it with 'envpick layers sign'",
+		TeamConfigVerify:         "team config %s failed verification: %w",
+		TeamConfigNoTrustedKeys:  "no trusted keys in ~/.envpick/team_keys",
+		TeamConfigBadSignature:   "signature does not match any trusted key",
+		TeamConfigSign:           "failed to write team config signature: %w",
+		TrustedKeysRead:          "failed to read trusted keys: %w",
+		NoCurrentConfig:          "no current configuration; pass a configuration name",
+		UnknownFormat:            "unknown format %q",
+		KeyNotFound:              "key %q not found in configuration %q",
+		KeyNotFoundAll:           "key %q not found in any configuration",
+		InvalidKey:               "invalid key %q: use letters, digits and underscores",
+		SetMissingValue:          "missing value: use KEY=VALUE or --from-stdin (got %q)",
+		ValueAndStdin:            "use either KEY=VALUE or --from-stdin, not both",
+		StdinRead:                "failed to read standard input: %w",
+		InvalidConfigName:        "invalid configuration name %q (use letters, digits, - and _, with at most one namespace prefix)",
+		ConfigExists:             "configuration %q already exists",
+		ConfigNameClash:          "cannot create %q: %q is already used as a configuration or namespace",
+		UnknownImportPolicy:      "unknown import policy %q (use merge, keep or overwrite)",
+		DotenvRead:               "failed to read dotenv file: %w",
+		DotenvParse:              "failed to parse %s: %w",
+		DotenvEmpty:              "%s contains no variables",
+		DotenvInvalidKey:         "line %d: expected a variable name",
+		DotenvMissingEquals:      "line %d: expected '=' after %s",
+		DotenvUnterminated:       "line %d: unterminated quoted value",
+		DotenvTrailingText:       "line %d: unexpected text after quoted value",
+		CaptureNoFilter:          "specify the variables to capture with --prefix or --keys",
+		CaptureNothing:           "no matching variables in the current environment",
+		ExportUnknownFormat:      "unknown export format %q (available: %s)",
+		ExportMultiline:          "%s contains a newline, which the %s format cannot represent",
+		ExportWrite:              "failed to write export: %w",
+		ActiveEnvWrite:           "failed to write %s: %w",
+		ShellStart:               "failed to start %s: %w",
+		NoCommands:               "configuration %q has no _commands",
+		CommandNotFound:          "command %q not found in configuration %q",
+		CommandStart:             "failed to run command %q: %w",
+		ConfigInvalidHookFailure: "configuration %q has invalid _hook_failure %q (use abort or warn)",
+		ConfigInvalidHookTimeout: "configuration %q has invalid _hook_timeout: %w",
+		HookFailed:               "%s hook of %q failed: %w",
+		HookTimeout:              "timed out after %s",
+		ConfigInvalidBool:        "configuration %q has invalid %s %q (use true or false)",
+		AuditWrite:               "failed to write audit log: %w",
+		ProtectedNotConfirmed:    "configuration %q is protected and was not confirmed",
+		ReasonRequired:           "configuration %q requires a reason (use --reason)",
+		NoPreviousConfig:         "no previous configuration to switch back to",
+		StateLock:                "failed to lock state file: %w",
+		StateRecover:             "failed to move corrupt state file aside: %w",
+		InvalidFileVersion:       "invalid format version %v",
+		FileVersionTooNew:        "format version %d is newer than this envpick supports (%d); please upgrade envpick",
+		MigrateFailed:            "failed to migrate %s: %w",
+		NoSession:                "no terminal session: $ENVPICK_SESSION is set by 'eval \"$(envpick init zsh)\"'",
+		InvalidSession:           "invalid %s %q: expected <shell pid>-<id>",
+		SessionWithLease:         "--for cannot be combined with --session",
+		InvalidLeaseDuration:     "invalid --for duration %s: must be positive",
+		BundleUntrustedSigner:    "bundle signer %s is not trusted: check the fingerprint with the sender and pass --from %s, or add it to %s",
+		TrustedSignersRead:       "failed to read trusted signers: %w",
+		TrustedSignersWrite:      "failed to add trusted signer: %w",
+		ReservedConfigName:       "invalid configuration name %q: %q is reserved",
+		CacheLock:                "failed to lock cache: %w",
+		CaptureInvalidKeys:       "cannot capture %s: not a configuration variable (shell, ENVPICK_ and _ metadata names are excluded)",
+		NoEditableConfigs:        "no configuration to change: every one only exists in the team config",
 	},
 	Messages: MessagesText{
-		SwitchedToConfig:   "Switched to configuration: %s\n",
-		SwitchedToConfigNS: "Switched to configuration: %s (namespace: %s)\n",
-		OpenedURL:          "Opened: %s\n",
+		SwitchedToConfig:        "Switched to configuration: %s\n",
+		SwitchedToConfigNS:      "Switched to configuration: %s (namespace: %s)\n",
+		OpenedURL:               "Opened: %s\n",
+		CacheCleared:            "Cleared resolved-value cache\n",
+		SharedBundle:            "Shared %q as %s (signed by %s)\n",
+		ReceivedBundle:          "Bundle %q signed by %s, created %s\n",
+		ReceiveConflict:         "Warning: configuration %q already exists and will be replaced\n",
+		WroteProfile:            "Wrote configuration: %s\n",
+		IdentityRecipient:       "Public key:  %s\n",
+		IdentitySigner:          "Signing key: %s (fingerprint %s)\n",
+		TeamLayerVerified:       "Team config: %s (signature verified)\n",
+		TeamLayerNone:           "Team config: none\n",
+		TeamConfigSigned:        "Signed %s: %s\n",
+		TeamConfigTrustHint:     "Add this public key to ~/.envpick/team_keys on every machine:\n  %s\n",
+		NoActiveConfigs:         "No active configurations\n",
+		NoDifferences:           "  No differences\n",
+		SetValue:                "Set %s in %s\n",
+		UnsetValue:              "Removed %s from %s\n",
+		CreatedConfig:           "Created configuration: %s\n",
+		CopiedConfig:            "Copied configuration %s to %s\n",
+		RenamedConfig:           "Renamed configuration %s to %s\n",
+		RemovedConfig:           "Removed configuration: %s\n",
+		ImportNewProfile:        "New configuration: %s\n",
+		ImportNoChanges:         "Configuration %s is already up to date\n",
+		CaptureMissingKey:       "Warning: %s is not set, skipping\n",
+		CapturePreview:          "Capturing %d variables into %s:\n",
+		ExportedConfig:          "Exported %s to %s\n",
+		SyncedActiveEnv:         "Wrote %s\n",
+		ActiveEnvSyncFailed:     "Warning: could not update active env files: %v\n",
+		NestedShell:             "Warning: already inside an envpick shell for %s; exiting will return to it\n",
+		EnteringShell:           "Entering shell for %s (exit to leave)\n",
+		LeftShell:               "Left shell for %s\n",
+		HookWarning:             "Warning: %v\n",
+		LeaseUntil:              "Lease: %s (until %s)\n",
+		LeaseExpired:            "Lease on %s expired; no configuration selected\n",
+		LeaseExpiredRestored:    "Lease on %s expired; switched back to %s\n",
+		NoHistory:               "No configuration switches recorded\n",
+		DeactivatedConfig:       "Deactivated configuration: %s\n",
+		NothingToDeactivate:     "No configuration is selected\n",
+		StateRecovered:          "Warning: %v; moved it to %s and starting with no selection\n",
+		MigrateStateCurrent:     "move current_config into the per-namespace [current] table",
+		MigrateConfigDefault:    "remove the unused top-level default key",
+		MigrateUpToDate:         "%s is up to date\n",
+		MigratePending:          "%s would be migrated:\n",
+		Migrated:                "Migrated %s:\n",
+		SwitchedToConfigSession: "Switched to configuration for this session: %s\n",
+		WhichUnresolved:         "Warning: skipped %s: %s\n",
+		UntrustedSigner:         "Warning: signer %s is not in your trusted signers; anyone can sign a bundle. Compare the fingerprint with the sender over another channel.\n",
+		TrustedSigner:           "Trusted signer %s (saved to %s)\n",
+		BundleExecutable:        "Runs commands or reads values from providers when used:\n",
+		AuditFailed:             "Warning: could not record the activation in the audit log: %v\n",
+		TeamLayerIgnored:        "Warning: ignoring team config %s: no trusted keys in %s\n",
+		SetSkippedTeam:          "Skipped %s: it only exists in the team config\n",
 	},
 	Formats: FormatsText{
-		ErrorPrefix:     "envpick: %v\n",
-		ActiveIndicator: " [*]",
-		ExportStatement: "export %s=%q",
-		PromptSuffix:    " ",
+		ErrorPrefix:        "envpick: %v\n",
+		ActiveIndicator:    " [*]",
+		ExportStatement:    "export %s=%q",
+		PromptSuffix:       " ",
+		BundleExtension:    ".envpick.age",
+		KeyAdded:           "  + %s\n",
+		KeyRemoved:         "  - %s\n",
+		KeyChanged:         "  ~ %s\n",
+		LayersConfig:       "%s\n",
+		LayersKey:          "  %s\t%s\n",
+		LayersRejected:     "  %s\tpersonal (ignored: not overridable)\n",
+		ShowHeader:         "Configuration: %s\n",
+		ShowVariables:      "Variables:\n",
+		ShowCommands:       "Commands:\n",
+		ShowMetadata:       "Metadata:\n",
+		ShowRow:            "  %s\t%s\n",
+		ShowVia:            "  (via %s)",
+		MaskedValue:        "******** [%s]",
+		EmptyValue:         "(empty)",
+		ListTableHeader:    "NAMESPACE\tCONFIG\tACTIVE\n",
+		ListTableRow:       "%s\t%s\t%s\n",
+		DefaultNamespace:   "(default)",
+		ActiveMarker:       "*",
+		TreeBranch:         "├── ",
+		TreeLastBranch:     "└── ",
+		TreeIndent:         "│   ",
+		TreeLastIndent:     "    ",
+		StatusTableHeader:  "NAMESPACE\tCONFIG\tSTATUS\tLEASE\tDIFFERING\n",
+		StatusTableRow:     "%s\t%s\t%s\t%s\t%s\n",
+		StatusCount:        "%s (%d/%d)",
+		WhichTableHeader:   "NAMESPACE\tDETECTED\tSCORE\tPERSISTED\tDIFFERING\n",
+		WhichTableRow:      "%s\t%s\t%s\t%s\t%s\n",
+		WhichScore:         "%.0f%% (%d/%d)",
+		NoMatch:            "-",
+		DiffHeader:         "%s -> %s\n",
+		DiffAdded:          "  + %s\t%s\n",
+		DiffRemoved:        "  - %s\t%s\n",
+		DiffChanged:        "  ~ %s\t%s -> %s\n",
+		UnifiedFrom:        "--- %s\n",
+		UnifiedTo:          "+++ %s\n",
+		UnifiedHunk:        "@@ -%d,%d +%d,%d @@\n",
+		Assignment:         "%s=%q",
+		GetAllRow:          "%s\t%s\n",
+		CaptureRow:         "  %s=%s\n",
+		ActiveEnvHeader:    "# Generated by envpick from the selected configurations. Do not edit.\n",
+		ProtectedIndicator: " \x1b[33m⚠ protected\x1b[0m",
+		UnsetStatement:     "unset %s",
+		LeaseExpiredStatus: "expired",
+		HistoryTableHeader: "TIME\tCONFIG\n",
+		HistoryTableRow:    "%s\t%s\n",
+		HistoryTime:        "2006-01-02 15:04",
+		MigrationStep:      "  v%d: %s\n",
+		SessionSuffix:      " (session)",
+		KeyExecutable:      "  ! %s = %s\n",
 	},
 	Prompts: PromptsText{
 		SelectConfiguration: "Select configuration:",
 		SelectWebURL:        "Select configuration to open web URL:",
+		ConfirmWriteProfile: "Write configuration %q? [y/N] ",
+		ConfirmReveal:       "Reveal secret values of %q on screen? [y/N] ",
+		ConfirmRemoveConfig: "Remove configuration %q from config.toml? [y/N] ",
+		SelectCommand:       "Select command:",
+		TypeProtectedName:   "%q is protected. Type its name to continue: ",
+		EnterReason:         "Reason for switching to %q: ",
+		ConfirmTrustSigner:  "Trust signer %s? [y/N] ",
 	},
 }
@@ -0,0 +1,511 @@
+package tomledit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Document is a TOML file edited line by line, so that comments, blank lines
+// and the order of tables and keys survive every change.
+type Document struct {
+	lines []string
+}
+
+// KeyValue is a single string key/value pair
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// bareKey matches keys that do not need quoting
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Parse splits data into an editable document
+func Parse(data []byte) *Document {
+	content := strings.TrimSuffix(string(data), "\n")
+	if content == "" {
+		return &Document{}
+	}
+	return &Document{lines: strings.Split(content, "\n")}
+}
+
+// Bytes returns the document content
+func (d *Document) Bytes() []byte {
+	if len(d.lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(d.lines, "\n") + "\n")
+}
+
+// table is the location of a [table] block within the document
+type table struct {
+	name   string
+	header int // index of the header line
+	start  int // first line of the block, including attached comments
+	end    int // one past the last key/value line of the block
+}
+
+// tables returns every [table] block in document order
+func (d *Document) tables() []table {
+	var (
+		result    []table
+		multiline string // closing delimiter while inside a multi-line string
+	)
+
+	for i, line := range d.lines {
+		trimmed := strings.TrimSpace(line)
+
+		if multiline != "" {
+			if strings.Count(trimmed, multiline)%2 == 1 {
+				multiline = ""
+			}
+			if len(result) > 0 {
+				result[len(result)-1].end = i + 1
+			}
+			continue
+		}
+
+		if name, ok := parseHeader(trimmed); ok {
+			start := i
+			for start > 0 && isComment(d.lines[start-1]) {
+				start--
+			}
+			if len(result) > 0 && result[len(result)-1].end > start {
+				start = result[len(result)-1].end
+			}
+			result = append(result, table{name: name, header: i, start: start, end: i + 1})
+			continue
+		}
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		for _, delim := range []string{`"""`, `'''`} {
+			if strings.Count(trimmed, delim)%2 == 1 {
+				multiline = delim
+				break
+			}
+		}
+		if len(result) > 0 {
+			result[len(result)-1].end = i + 1
+		}
+	}
+
+	return result
+}
+
+// findTable returns the block for the named table
+func (d *Document) findTable(name string) (table, bool) {
+	for _, t := range d.tables() {
+		if t.name == name {
+			return t, true
+		}
+	}
+	return table{}, false
+}
+
+// keyTable returns the block holding the keys of the named table, or of the
+// root table (the keys before the first header) for the empty name
+func (d *Document) keyTable(name string) (table, bool) {
+	if name != "" {
+		return d.findTable(name)
+	}
+
+	root := table{header: -1}
+	end := len(d.lines)
+	if tables := d.tables(); len(tables) > 0 {
+		end = tables[0].start
+	}
+	for i := 0; i < end; i++ {
+		if kv, ok := d.parseKeyValue(i); ok {
+			root.end = kv.last + 1
+			i = kv.last
+		}
+	}
+	return root, true
+}
+
+// HasTable reports whether the document contains a [name] table
+func (d *Document) HasTable(name string) bool {
+	_, ok := d.findTable(name)
+	return ok
+}
+
+// AppendTable adds a [name] table with the given values at the end of the document
+func (d *Document) AppendTable(name string, values []KeyValue) {
+	block := []string{FormatHeader(name)}
+	for _, kv := range values {
+		block = append(block, FormatKeyValue(kv.Key, kv.Value))
+	}
+	d.appendBlock(block)
+}
+
+// appendBlock adds lines at the end of the document, separated by one blank line
+func (d *Document) appendBlock(block []string) {
+	for len(d.lines) > 0 && strings.TrimSpace(d.lines[len(d.lines)-1]) == "" {
+		d.lines = d.lines[:len(d.lines)-1]
+	}
+	if len(d.lines) > 0 {
+		d.lines = append(d.lines, "")
+	}
+	d.lines = append(d.lines, block...)
+}
+
+// ReplaceTable replaces the keys of the [name] table in place, keeping its position
+// and the comments above it. The table is appended if it does not exist.
+func (d *Document) ReplaceTable(name string, values []KeyValue) {
+	t, ok := d.findTable(name)
+	if !ok {
+		d.AppendTable(name, values)
+		return
+	}
+
+	body := make([]string, 0, len(values))
+	for _, kv := range values {
+		body = append(body, FormatKeyValue(kv.Key, kv.Value))
+	}
+
+	rest := append([]string{}, d.lines[t.end:]...)
+	d.lines = append(append(d.lines[:t.header+1], body...), rest...)
+}
+
+// keyValue is the location of a key = value line within the document
+type keyValue struct {
+	key    string
+	line   int    // line of the key
+	last   int    // last line of the value (differs for multi-line strings)
+	prefix string // text up to the start of the value
+	suffix string // text after the end of the value on the last line (e.g. a comment)
+}
+
+// findKey returns the location of key within the [table] block
+func (d *Document) findKey(t table, key string) (keyValue, bool) {
+	for i := t.header + 1; i < t.end; i++ {
+		kv, ok := d.parseKeyValue(i)
+		if !ok {
+			continue
+		}
+		if kv.key == key {
+			return kv, true
+		}
+		i = kv.last
+	}
+	return keyValue{}, false
+}
+
+// parseKeyValue parses the key/value pair starting on line i
+func (d *Document) parseKeyValue(i int) (keyValue, bool) {
+	line := d.lines[i]
+	if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return keyValue{}, false
+	}
+
+	eq := strings.IndexByte(line, '=')
+	if eq < 0 {
+		return keyValue{}, false
+	}
+	key, ok := parseKey(strings.TrimSpace(line[:eq]))
+	if !ok {
+		return keyValue{}, false
+	}
+
+	start := eq + 1
+	for start < len(line) && (line[start] == ' ' || line[start] == '\t') {
+		start++
+	}
+	kv := keyValue{key: key, line: i, last: i, prefix: line[:start]}
+	rest := line[start:]
+
+	// Multi-line strings end on a later line
+	for _, delim := range []string{`"""`, `'''`} {
+		if !strings.HasPrefix(rest, delim) {
+			continue
+		}
+		if end := strings.Index(rest[len(delim):], delim); end >= 0 {
+			kv.suffix = rest[len(delim)+end+len(delim):]
+			return kv, true
+		}
+		for j := i + 1; j < len(d.lines); j++ {
+			if end := strings.Index(d.lines[j], delim); end >= 0 {
+				kv.last = j
+				kv.suffix = d.lines[j][end+len(delim):]
+				return kv, true
+			}
+		}
+		return keyValue{}, false
+	}
+
+	kv.suffix = rest[valueLength(rest):]
+	return kv, true
+}
+
+// valueLength returns the length of the single-line value at the start of s
+func valueLength(s string) int {
+	if s == "" {
+		return 0
+	}
+	switch s[0] {
+	case '"':
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+				continue
+			}
+			if s[i] == '"' {
+				return i + 1
+			}
+		}
+	case '\'':
+		if end := strings.IndexByte(s[1:], '\''); end >= 0 {
+			return end + 2
+		}
+	default:
+		if end := strings.IndexByte(s, '#'); end >= 0 {
+			return len(strings.TrimRight(s[:end], " \t"))
+		}
+		return len(strings.TrimRight(s, " \t"))
+	}
+	return len(s)
+}
+
+// parseKey returns the unquoted form of a single (non-dotted) key
+func parseKey(raw string) (string, bool) {
+	if bareKey.MatchString(raw) {
+		return raw, true
+	}
+	if len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') && raw[len(raw)-1] == raw[0] {
+		inner := raw[1 : len(raw)-1]
+		if raw[0] == '"' {
+			inner = strings.ReplaceAll(inner, `\"`, `"`)
+			inner = strings.ReplaceAll(inner, `\\`, `\`)
+		}
+		return inner, true
+	}
+	return "", false
+}
+
+// SetValue sets key to a string value in the [name] table, replacing the
+// existing value in place (keeping any trailing comment) or adding the key after
+// the table's last key. The empty name is the root table.
+// Returns false if the table does not exist.
+func (d *Document) SetValue(name, key, value string) bool {
+	return d.SetRawValue(name, key, FormatString(value))
+}
+
+// SetRawValue is like SetValue, but writes raw as is, for values that are not
+// strings (e.g. version = 1)
+func (d *Document) SetRawValue(name, key, raw string) bool {
+	t, ok := d.keyTable(name)
+	if !ok {
+		return false
+	}
+
+	if kv, found := d.findKey(t, key); found {
+		d.lines[kv.line] = kv.prefix + raw + kv.suffix
+		d.lines = append(d.lines[:kv.line+1], d.lines[kv.last+1:]...)
+		return true
+	}
+
+	added := []string{FormatKey(key) + " = " + raw}
+	if t.header < 0 && t.end == 0 && len(d.lines) > 0 && strings.TrimSpace(d.lines[0]) != "" {
+		// First root key: keep it apart from the tables below
+		added = append(added, "")
+	}
+	rest := append([]string{}, d.lines[t.end:]...)
+	d.lines = append(append(d.lines[:t.end], added...), rest...)
+	return true
+}
+
+// DeleteValue removes key from the [name] table, or from the root table for the
+// empty name. Returns false if the table or key does not exist.
+func (d *Document) DeleteValue(name, key string) bool {
+	t, ok := d.keyTable(name)
+	if !ok {
+		return false
+	}
+
+	kv, found := d.findKey(t, key)
+	if !found {
+		return false
+	}
+	d.lines = append(d.lines[:kv.line], d.lines[kv.last+1:]...)
+	return true
+}
+
+// RenameTable renames the [name] table and its metadata subtables in place.
+// Returns false if the table does not exist.
+func (d *Document) RenameTable(name, newName string) bool {
+	found := false
+	for _, t := range d.tables() {
+		if t.name == name {
+			d.lines[t.header] = FormatHeader(newName) + headerSuffix(d.lines[t.header])
+			found = true
+		} else if sub, ok := strings.CutPrefix(t.name, name+"._"); ok {
+			d.lines[t.header] = FormatHeader(newName+"._"+sub) + headerSuffix(d.lines[t.header])
+		}
+	}
+	return found
+}
+
+// CopyTable appends a copy of the [name] table and its metadata subtables as
+// [newName], keeping values and comments as written. Returns false if the table
+// does not exist.
+func (d *Document) CopyTable(name, newName string) bool {
+	var copied [][]string
+	for _, t := range d.tables() {
+		target := newName
+		if t.name != name {
+			sub, ok := strings.CutPrefix(t.name, name+"._")
+			if !ok {
+				continue
+			}
+			target = newName + "._" + sub
+		}
+
+		block := []string{FormatHeader(target) + headerSuffix(d.lines[t.header])}
+		block = append(block, d.lines[t.header+1:t.end]...)
+		copied = append(copied, block)
+	}
+	if len(copied) == 0 {
+		return false
+	}
+
+	for _, block := range copied {
+		d.appendBlock(block)
+	}
+	return true
+}
+
+// DeleteTable removes the [name] table and its metadata subtables (e.g. [name._commands]).
+// Returns false if the table does not exist.
+func (d *Document) DeleteTable(name string) bool {
+	found := false
+	tables := d.tables()
+	// Delete from the end so that earlier line indexes stay valid
+	for i := len(tables) - 1; i >= 0; i-- {
+		t := tables[i]
+		if t.name != name && !strings.HasPrefix(t.name, name+"._") {
+			continue
+		}
+		found = true
+		end := t.end
+		// Swallow the blank lines that separated the block from the next one
+		for end < len(d.lines) && strings.TrimSpace(d.lines[end]) == "" {
+			end++
+		}
+		d.lines = append(d.lines[:t.start], d.lines[end:]...)
+	}
+	return found
+}
+
+// parseHeader returns the dotted table name of a [table] header line
+func parseHeader(line string) (string, bool) {
+	if !strings.HasPrefix(line, "[") || strings.HasPrefix(line, "[[") {
+		return "", false
+	}
+
+	var (
+		parts   []string
+		current strings.Builder
+		quote   byte
+	)
+	for i := 1; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteByte(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			parts = append(parts, strings.TrimSpace(current.String()))
+			current.Reset()
+		case c == ']':
+			parts = append(parts, strings.TrimSpace(current.String()))
+			rest := strings.TrimSpace(line[i+1:])
+			if rest != "" && !strings.HasPrefix(rest, "#") {
+				return "", false
+			}
+			return strings.Join(parts, "."), true
+		default:
+			current.WriteByte(c)
+		}
+	}
+	return "", false
+}
+
+// headerSuffix returns what follows the closing bracket of a header line (e.g. a comment)
+func headerSuffix(line string) string {
+	end := len(line)
+	if hash := strings.IndexByte(line, '#'); hash >= 0 {
+		end = hash
+	}
+	if bracket := strings.LastIndexByte(line[:end], ']'); bracket >= 0 {
+		return line[bracket+1:]
+	}
+	return ""
+}
+
+// isComment reports whether line is a comment line
+func isComment(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "#")
+}
+
+// FormatHeader returns the header line for a dotted table name
+func FormatHeader(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = FormatKey(part)
+	}
+	return "[" + strings.Join(parts, ".") + "]"
+}
+
+// FormatKeyValue returns a key = "value" line
+func FormatKeyValue(key, value string) string {
+	return FormatKey(key) + " = " + FormatString(value)
+}
+
+// FormatKey returns key, quoted if it is not a bare key
+func FormatKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	return FormatString(key)
+}
+
+// FormatString returns value as a TOML basic string
+func FormatString(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,320 @@
+package tomledit
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleDocument = `# envpick configuration
+
+[personal]
+API_KEY = "sk-personal" # my own key
+MODEL = "claude-sonnet-4-5"
+
+# Work account
+[work]
+API_KEY = "sk-work"
+NOTES = """
+multi-line
+[not.a.table]
+"""
+
+[db.local]
+DB_HOST = "localhost"
+`
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		name         string
+		line         string
+		expectedName string
+		expectedOk   bool
+	}{
+		{name: "simple", line: "[work]", expectedName: "work", expectedOk: true},
+		{name: "dotted", line: "[db.local]", expectedName: "db.local", expectedOk: true},
+		{name: "quoted", line: `["my app".local] # comment`, expectedName: "my app.local", expectedOk: true},
+		{name: "array of tables", line: "[[items]]", expectedOk: false},
+		{name: "key value", line: `KEY = "[x]"`, expectedOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, ok := parseHeader(tt.line)
+			assert.Equal(t, tt.expectedOk, ok, "ok should match")
+			assert.Equal(t, tt.expectedName, name, "name should match")
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	doc := Parse([]byte(sampleDocument))
+	assert.Equal(t, sampleDocument, string(doc.Bytes()), "unchanged document should round-trip")
+}
+
+func TestHasTable(t *testing.T) {
+	doc := Parse([]byte(sampleDocument))
+
+	assert.True(t, doc.HasTable("personal"))
+	assert.True(t, doc.HasTable("db.local"))
+	assert.False(t, doc.HasTable("not.a.table"), "headers inside multi-line strings should be ignored")
+	assert.False(t, doc.HasTable("prod"))
+}
+
+func TestAppendTable(t *testing.T) {
+	doc := Parse([]byte(sampleDocument))
+	doc.AppendTable("db.prod", []KeyValue{
+		{Key: "DB_HOST", Value: "prod.db"},
+		{Key: "PASSWORD", Value: "p\"a\\ss\nword"},
+	})
+
+	expected := sampleDocument + `
+[db.prod]
+DB_HOST = "prod.db"
+PASSWORD = "p\"a\\ss\nword"
+`
+	assert.Equal(t, expected, string(doc.Bytes()))
+
+	var decoded map[string]map[string]map[string]string
+	_, err := toml.Decode(string(doc.Bytes())[len(sampleDocument):], &decoded)
+	require.NoError(t, err, "appended table should be valid TOML")
+	assert.Equal(t, "p\"a\\ss\nword", decoded["db"]["prod"]["PASSWORD"])
+}
+
+func TestReplaceTable(t *testing.T) {
+	doc := Parse([]byte(sampleDocument))
+	doc.ReplaceTable("personal", []KeyValue{{Key: "API_KEY", Value: "sk-new"}})
+
+	expected := `# envpick configuration
+
+[personal]
+API_KEY = "sk-new"
+
+# Work account
+[work]
+API_KEY = "sk-work"
+NOTES = """
+multi-line
+[not.a.table]
+"""
+
+[db.local]
+DB_HOST = "localhost"
+`
+	assert.Equal(t, expected, string(doc.Bytes()))
+
+	doc.ReplaceTable("prod", []KeyValue{{Key: "API_KEY", Value: "sk-prod"}})
+	assert.True(t, doc.HasTable("prod"), "missing table should be appended")
+}
+
+func TestDeleteTable(t *testing.T) {
+	doc := Parse([]byte(sampleDocument))
+
+	assert.True(t, doc.DeleteTable("work"), "work should be deleted")
+	assert.False(t, doc.DeleteTable("work"), "deleting twice should report missing")
+
+	expected := `# envpick configuration
+
+[personal]
+API_KEY = "sk-personal" # my own key
+MODEL = "claude-sonnet-4-5"
+
+[db.local]
+DB_HOST = "localhost"
+`
+	assert.Equal(t, expected, string(doc.Bytes()))
+}
+
+func TestDeleteTableWithMetadataSubtables(t *testing.T) {
+	doc := Parse([]byte(`[work]
+API_KEY = "x"
+
+[work._commands]
+console = "psql"
+
+[workshop]
+API_KEY = "y"
+`))
+
+	assert.True(t, doc.DeleteTable("work"))
+	assert.Equal(t, `[workshop]
+API_KEY = "y"
+`, string(doc.Bytes()))
+}
+
+func TestFormatKey(t *testing.T) {
+	assert.Equal(t, "API_KEY", FormatKey("API_KEY"))
+	assert.Equal(t, `"my key"`, FormatKey("my key"))
+	assert.Equal(t, `"a.b"`, FormatKey("a.b"))
+}
+
+func TestSetValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    string
+		key      string
+		value    string
+		expected string
+	}{
+		{
+			name:  "replace keeps comment",
+			table: "personal",
+			key:   "API_KEY",
+			value: "sk-new",
+			expected: `# envpick configuration
+
+[personal]
+API_KEY = "sk-new" # my own key
+MODEL = "claude-sonnet-4-5"
+`,
+		},
+		{
+			name:  "add after last key",
+			table: "personal",
+			key:   "TIMEOUT",
+			value: "300000",
+			expected: `# envpick configuration
+
+[personal]
+API_KEY = "sk-personal" # my own key
+MODEL = "claude-sonnet-4-5"
+TIMEOUT = "300000"
+`,
+		},
+		{
+			name:  "replace multi-line value",
+			table: "work",
+			key:   "NOTES",
+			value: "single",
+			expected: `# Work account
+[work]
+API_KEY = "sk-work"
+NOTES = "single"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := Parse([]byte(sampleDocument))
+			require.True(t, doc.SetValue(tt.table, tt.key, tt.value), "SetValue should find the table")
+			assert.Contains(t, string(doc.Bytes()), tt.expected)
+
+			var decoded map[string]interface{}
+			_, err := toml.Decode(string(doc.Bytes()), &decoded)
+			require.NoError(t, err, "edited document should be valid TOML")
+		})
+	}
+}
+
+func TestSetValueMissingTable(t *testing.T) {
+	doc := Parse([]byte(sampleDocument))
+	assert.False(t, doc.SetValue("prod", "API_KEY", "x"))
+	assert.Equal(t, sampleDocument, string(doc.Bytes()), "document should be unchanged")
+}
+
+func TestSetValueQuotedKey(t *testing.T) {
+	doc := Parse([]byte("[work]\n\"my key\" = 'literal' # note\n"))
+	require.True(t, doc.SetValue("work", "my key", "new"))
+	assert.Equal(t, "[work]\n\"my key\" = \"new\" # note\n", string(doc.Bytes()))
+}
+
+func TestSetRawValueRoot(t *testing.T) {
+	doc := Parse([]byte(sampleDocument))
+	require.True(t, doc.SetRawValue("", "version", "1"))
+	assert.Equal(t, "version = 1\n\n"+sampleDocument, string(doc.Bytes()), "first root key goes on top")
+
+	require.True(t, doc.SetRawValue("", "version", "2"))
+	require.True(t, doc.SetValue("", "owner", "me"))
+	assert.Equal(t, "version = 2\nowner = \"me\"\n\n"+sampleDocument, string(doc.Bytes()))
+
+	var decoded map[string]interface{}
+	_, err := toml.Decode(string(doc.Bytes()), &decoded)
+	require.NoError(t, err, "edited document should be valid TOML")
+	assert.Equal(t, int64(2), decoded["version"])
+}
+
+func TestDeleteValueRoot(t *testing.T) {
+	doc := Parse([]byte("default = \"dev\" # legacy\n\n[dev]\ndefault = \"x\"\n"))
+	assert.True(t, doc.DeleteValue("", "default"))
+	assert.False(t, doc.DeleteValue("", "default"), "only the root key should be deleted")
+	assert.Equal(t, "\n[dev]\ndefault = \"x\"\n", string(doc.Bytes()))
+}
+
+func TestDeleteValue(t *testing.T) {
+	doc := Parse([]byte(sampleDocument))
+
+	assert.True(t, doc.DeleteValue("work", "NOTES"), "multi-line value should be deleted")
+	assert.True(t, doc.DeleteValue("personal", "MODEL"))
+	assert.False(t, doc.DeleteValue("personal", "MODEL"), "deleting twice should report missing")
+	assert.False(t, doc.DeleteValue("prod", "MODEL"), "missing table should report missing")
+
+	expected := `# envpick configuration
+
+[personal]
+API_KEY = "sk-personal" # my own key
+
+# Work account
+[work]
+API_KEY = "sk-work"
+
+[db.local]
+DB_HOST = "localhost"
+`
+	assert.Equal(t, expected, string(doc.Bytes()))
+}
+
+func TestRenameTable(t *testing.T) {
+	doc := Parse([]byte(`# Work account
+[work] # main
+API_KEY = "x"
+
+[work._commands]
+console = "psql"
+`))
+
+	assert.True(t, doc.RenameTable("work", "db.work"))
+	assert.False(t, doc.RenameTable("work", "other"), "old name should be gone")
+	assert.Equal(t, `# Work account
+[db.work] # main
+API_KEY = "x"
+
+[db.work._commands]
+console = "psql"
+`, string(doc.Bytes()))
+}
+
+func TestCopyTable(t *testing.T) {
+	doc := Parse([]byte(`[work]
+# token from the vault
+API_KEY = "cmd://pass show work"
+
+[work._commands]
+console = "psql"
+
+[personal]
+API_KEY = "y"
+`))
+
+	assert.True(t, doc.CopyTable("work", "staging"))
+	assert.False(t, doc.CopyTable("missing", "other"))
+	assert.Equal(t, `[work]
+# token from the vault
+API_KEY = "cmd://pass show work"
+
+[work._commands]
+console = "psql"
+
+[personal]
+API_KEY = "y"
+
+[staging]
+# token from the vault
+API_KEY = "cmd://pass show work"
+
+[staging._commands]
+console = "psql"
+`, string(doc.Bytes()))
+}